@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// backupSuffix is appended to name of replaced destination in backup mode.
+const backupSuffix = "~"
+
+// maxTempAttempts is count of attempts to find unused temporary file name.
+const maxTempAttempts = 100
+
+// maxSymlinks is count of symlinks followed to resolve destination.
+const maxSymlinks = 40
+
+var (
+	ErrSameFile          = errors.New("source and destination are the same file")
+	ErrDestinationExists = errors.New("destination already exists")
+	ErrResumeOverwrite   = errors.New("resume can't be combined with no-clobber or backup")
+	ErrVerifyUnsupported = errors.New("verification requires regular destination file")
+)
+
+// checkSameFile returns ErrSameFile if srcFile and toPath are the same file,
+// e.g. hard links or paths through symlinks.
+func checkSameFile(srcFile *os.File, toPath string) error {
+	dstInfo, err := os.Stat(toPath)
+	if err != nil {
+		return nil
+	}
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("can't get file info for %s: %w", srcFile.Name(), err)
+	}
+
+	if os.SameFile(srcInfo, dstInfo) {
+		return ErrSameFile
+	}
+
+	return nil
+}
+
+// checkNoClobber returns ErrDestinationExists if toPath exists in no-clobber mode.
+func checkNoClobber(toPath string, cfg config) error {
+	if !cfg.noClobber {
+		return nil
+	}
+
+	if _, err := os.Lstat(toPath); err == nil {
+		return ErrDestinationExists
+	}
+
+	return nil
+}
+
+// resolveDestination returns path of file to write toPath into. Symlinks
+// are followed, so they are kept and their targets are replaced. inPlace is
+// set if the file exists and isn't a regular file, like a device or FIFO,
+// so it is written in place instead of replacing it by a temporary file.
+func resolveDestination(toPath string) (path string, inPlace bool, err error) {
+	path = toPath
+
+	for i := 0; i < maxSymlinks; i++ {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return path, false, nil
+		}
+
+		if err != nil {
+			return "", false, fmt.Errorf("can't get file info for %s: %w", path, err)
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			return path, !info.Mode().IsRegular(), nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", false, fmt.Errorf("can't read symlink %s: %w", path, err)
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+
+		path = target
+	}
+
+	return "", false, fmt.Errorf("can't resolve %s: too many symlinks", toPath)
+}
+
+// createTemp creates temporary file in directory of toPath, so it can be
+// renamed to toPath. The file gets mode of existing toPath, else default
+// mode of new files with umask applied.
+func createTemp(toPath string) (*os.File, error) {
+	dir, base := filepath.Split(toPath)
+
+	var perm os.FileMode
+	if info, err := os.Stat(toPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	for i := 0; i < maxTempAttempts; i++ {
+		suffix := strconv.FormatInt(time.Now().UnixNano()+int64(i), 36)
+		name := filepath.Join(dir, "."+base+"."+suffix+".tmp")
+
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if os.IsExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("can't create temporary file for %s: %w", toPath, err)
+		}
+
+		if perm == 0 {
+			return f, nil
+		}
+
+		// Mode of existing file is kept as is, without umask.
+		if err := f.Chmod(perm); err != nil {
+			f.Close()
+			os.Remove(name)
+
+			return nil, fmt.Errorf("can't change mode of %s: %w", name, err)
+		}
+
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("can't create temporary file for %s: too many attempts", toPath)
+}
+
+// commitTemp flushes tmpFile to disk and moves it to toPath. Existing toPath
+// is replaced, linked with backupSuffix in backup mode or kept in no-clobber
+// mode, then ErrDestinationExists is returned. toPath exists all the time
+// it is replaced.
+func commitTemp(tmpFile *os.File, toPath string, cfg config) error {
+	if err := tmpFile.Sync(); err != nil {
+		return fmt.Errorf("can't sync file %s: %w", tmpFile.Name(), err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("can't close file %s: %w", tmpFile.Name(), err)
+	}
+
+	if cfg.noClobber {
+		// Link fails if toPath exists, unlike rename which replaces it.
+		if err := os.Link(tmpFile.Name(), toPath); err != nil {
+			if os.IsExist(err) {
+				return ErrDestinationExists
+			}
+
+			return fmt.Errorf("can't create file %s: %w", toPath, err)
+		}
+
+		if err := os.Remove(tmpFile.Name()); err != nil {
+			return fmt.Errorf("can't remove temporary file %s: %w", tmpFile.Name(), err)
+		}
+
+		return syncDir(filepath.Dir(toPath))
+	}
+
+	if cfg.backup {
+		if err := backupFile(toPath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpFile.Name(), toPath); err != nil {
+		return fmt.Errorf("can't rename %s to %s: %w", tmpFile.Name(), toPath, err)
+	}
+
+	return syncDir(filepath.Dir(toPath))
+}
+
+// backupFile links toPath with backupSuffix replacing previous backup,
+// so toPath is kept until it is replaced by rename.
+func backupFile(toPath string) error {
+	backupPath := toPath + backupSuffix
+
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't remove backup file %s: %w", backupPath, err)
+	}
+
+	if err := os.Link(toPath, backupPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't backup file %s: %w", toPath, err)
+	}
+
+	return nil
+}
+
+// syncDir flushes directory entries of dir to disk, so renames are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("can't open dir %s: %w", dir, err)
+	}
+
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("can't sync dir %s: %w", dir, err)
+	}
+
+	return nil
+}
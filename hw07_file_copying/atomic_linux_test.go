@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCopyInPlace(t *testing.T) {
+	expected, err := ioutil.ReadFile("./testdata/out_offset0_limit10.txt")
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	t.Run("FIFO", func(t *testing.T) {
+		dir := tempDir(t)
+		to := filepath.Join(dir, "out.fifo")
+
+		if err := syscall.Mkfifo(to, 0o600); err != nil {
+			t.Fatalf("unexpected error in Mkfifo: %v", err)
+		}
+
+		received := make(chan []byte)
+
+		go func() {
+			data, _ := ioutil.ReadFile(to)
+			received <- data
+		}()
+
+		if err := Copy("./testdata/input.txt", to, 0, 10, WithProgress(ProgressNone)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if data := <-received; string(data) != string(expected) {
+			t.Fatalf("unexpected data read from FIFO: %q, expected: %q", data, expected)
+		}
+
+		info, err := os.Lstat(to)
+		if err != nil {
+			t.Fatalf("unexpected error in Lstat: %v", err)
+		}
+
+		if info.Mode()&os.ModeNamedPipe == 0 {
+			t.Fatalf("unexpected mode of %s: %v, FIFO is replaced", to, info.Mode())
+		}
+
+		requireDirEntries(t, dir, "out.fifo")
+	})
+
+	t.Run("FIFO/Verify", func(t *testing.T) {
+		to := filepath.Join(tempDir(t), "out.fifo")
+
+		if err := syscall.Mkfifo(to, 0o600); err != nil {
+			t.Fatalf("unexpected error in Mkfifo: %v", err)
+		}
+
+		err := Copy("./testdata/input.txt", to, 0, 10, WithVerify())
+		if err != ErrVerifyUnsupported {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrVerifyUnsupported,
+			)
+		}
+	})
+}
+
+func TestCopyNewFileMode(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(0o027))
+
+	to := filepath.Join(tempDir(t), "out.txt")
+
+	if err := Copy("./testdata/input.txt", to, 0, 10); err != nil {
+		t.Fatalf("unexpected error in Copy: %v", err)
+	}
+
+	requireMode(t, to, 0o640)
+}
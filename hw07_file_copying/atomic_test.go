@@ -0,0 +1,212 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyAtomic(t *testing.T) {
+	expected, err := ioutil.ReadFile("./testdata/out_offset0_limit10.txt")
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	t.Run("SameFile", func(t *testing.T) {
+		from := tempFile(t, []byte("content"))
+
+		err := Copy(from, from, 0, 0)
+		if err != ErrSameFile {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrSameFile,
+			)
+		}
+
+		requireFileContent(t, from, []byte("content"))
+	})
+
+	t.Run("SameFile/HardLink", func(t *testing.T) {
+		from := tempFile(t, []byte("content"))
+		to := from + ".link"
+
+		if err := os.Link(from, to); err != nil {
+			t.Fatalf("unexpected error in Link: %v", err)
+		}
+
+		err := Copy(from, to, 0, 0)
+		if err != ErrSameFile {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrSameFile,
+			)
+		}
+
+		requireFileContent(t, from, []byte("content"))
+	})
+
+	t.Run("FailedCopy", func(t *testing.T) {
+		withStdin(t, []byte("12"))
+		to := tempFile(t, []byte("old content"))
+
+		err := Copy("-", to, 3, 0)
+		if err != ErrOffsetExceedsFileSize {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrOffsetExceedsFileSize,
+			)
+		}
+
+		requireFileContent(t, to, []byte("old content"))
+		requireDirEntries(t, filepath.Dir(to), "out.txt")
+	})
+
+	t.Run("KeepMode", func(t *testing.T) {
+		to := tempFile(t, []byte("old content"))
+
+		if err := os.Chmod(to, 0o600); err != nil {
+			t.Fatalf("unexpected error in Chmod: %v", err)
+		}
+
+		if err := Copy("./testdata/input.txt", to, 0, 10); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+		requireMode(t, to, 0o600)
+	})
+
+	t.Run("NoClobber", func(t *testing.T) {
+		to := tempFile(t, []byte("old content"))
+
+		err := Copy("./testdata/input.txt", to, 0, 10, WithNoClobber())
+		if err != ErrDestinationExists {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrDestinationExists,
+			)
+		}
+
+		requireFileContent(t, to, []byte("old content"))
+		requireDirEntries(t, filepath.Dir(to), "out.txt")
+	})
+
+	t.Run("NoClobber/MissingDestination", func(t *testing.T) {
+		to := filepath.Join(tempDir(t), "out.txt")
+
+		if err := Copy("./testdata/input.txt", to, 0, 10, WithNoClobber()); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+		requireDirEntries(t, filepath.Dir(to), "out.txt")
+	})
+
+	t.Run("Backup", func(t *testing.T) {
+		to := tempFile(t, []byte("old content"))
+
+		if err := Copy("./testdata/input.txt", to, 0, 10, WithBackup()); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+		requireFileContent(t, to+"~", []byte("old content"))
+		requireDirEntries(t, filepath.Dir(to), "out.txt", "out.txt~")
+	})
+
+	t.Run("Backup/Replace", func(t *testing.T) {
+		to := tempFile(t, []byte("old content"))
+
+		if err := ioutil.WriteFile(to+"~", []byte("older content"), 0o644); err != nil {
+			t.Fatalf("unexpected error in WriteFile: %v", err)
+		}
+
+		if err := Copy("./testdata/input.txt", to, 0, 10, WithBackup()); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+		requireFileContent(t, to+"~", []byte("old content"))
+		requireDirEntries(t, filepath.Dir(to), "out.txt", "out.txt~")
+	})
+
+	t.Run("Symlink", func(t *testing.T) {
+		target := tempFile(t, []byte("old content"))
+		to := filepath.Join(filepath.Dir(target), "link.txt")
+
+		if err := os.Chmod(target, 0o600); err != nil {
+			t.Fatalf("unexpected error in Chmod: %v", err)
+		}
+
+		if err := os.Symlink("out.txt", to); err != nil {
+			t.Fatalf("unexpected error in Symlink: %v", err)
+		}
+
+		if err := Copy("./testdata/input.txt", to, 0, 10); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if link, err := os.Readlink(to); err != nil || link != "out.txt" {
+			t.Fatalf("unexpected symlink %s: %q, %v", to, link, err)
+		}
+
+		requireFileContent(t, target, expected)
+		requireMode(t, target, 0o600)
+		requireDirEntries(t, filepath.Dir(to), "link.txt", "out.txt")
+	})
+
+	t.Run("Symlink/Dangling", func(t *testing.T) {
+		dir := tempDir(t)
+		to := filepath.Join(dir, "link.txt")
+
+		if err := os.Symlink("out.txt", to); err != nil {
+			t.Fatalf("unexpected error in Symlink: %v", err)
+		}
+
+		if err := Copy("./testdata/input.txt", to, 0, 10); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, filepath.Join(dir, "out.txt"), expected)
+		requireDirEntries(t, dir, "link.txt", "out.txt")
+	})
+
+	t.Run("ResumeOverwrite", func(t *testing.T) {
+		to := tempFile(t, nil)
+
+		err := Copy("./testdata/input.txt", to, 0, 10, WithResume(0), WithBackup())
+		if err != ErrResumeOverwrite {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrResumeOverwrite,
+			)
+		}
+	})
+}
+
+// requireDirEntries checks that dir contains only files with names,
+// so no temporary files are left.
+func requireDirEntries(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error in ReadDir: %v", err)
+	}
+
+	got := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+
+	if len(got) != len(names) {
+		t.Fatalf("unexpected entries of %s: %v, expected: %v", dir, got, names)
+	}
+
+	for i := range got {
+		if got[i] != names[i] {
+			t.Fatalf("unexpected entries of %s: %v, expected: %v", dir, got, names)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// bwlimitSlices is count of reads per second of rate-limited reader,
+	// so the rate is kept smooth enough for progress bar.
+	bwlimitSlices = 10
+	// maxBwlimitLag is a lag after which rate limiter forgets
+	// unused bandwidth instead of allowing a long burst.
+	maxBwlimitLag = time.Second
+)
+
+var ErrInvalidBandwidth = errors.New("invalid bandwidth")
+
+var bandwidthRegexp = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([A-Za-z]*)(?:/s)?$`)
+
+var bandwidthUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"k":   1 << 10,
+	"KiB": 1 << 10,
+	"KB":  1e3,
+	"M":   1 << 20,
+	"m":   1 << 20,
+	"MiB": 1 << 20,
+	"MB":  1e6,
+	"G":   1 << 30,
+	"g":   1 << 30,
+	"GiB": 1 << 30,
+	"GB":  1e9,
+}
+
+// ParseBandwidth returns count of bytes per second from s like "10MiB/s",
+// "500K" or "1.5GB/s". K, M and G without B are binary units.
+func ParseBandwidth(s string) (int64, error) {
+	matches := bandwidthRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidBandwidth, s)
+	}
+
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", ErrInvalidBandwidth, s, err)
+	}
+
+	unit, ok := bandwidthUnits[matches[2]]
+	if !ok {
+		return 0, fmt.Errorf("%w %q: unknown unit %q", ErrInvalidBandwidth, s, matches[2])
+	}
+
+	rate := int64(value * unit)
+	if rate <= 0 {
+		return 0, fmt.Errorf("%w %q: must be positive", ErrInvalidBandwidth, s)
+	}
+
+	return rate, nil
+}
+
+// rateLimiter limits count of read bytes per second. It is safe for
+// concurrent use, so parallel jobs and files of a tree share the limit.
+// Methods of nil rateLimiter don't limit anything.
+type rateLimiter struct {
+	rate int64
+
+	mu    sync.Mutex
+	start time.Time
+	total int64
+}
+
+func newRateLimiter(rate int64) *rateLimiter {
+	return &rateLimiter{rate: rate}
+}
+
+// wait blocks until n more bytes are allowed by the rate.
+func (l *rateLimiter) wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+
+	now := time.Now()
+	if l.start.IsZero() || now.Sub(l.due()) > maxBwlimitLag {
+		l.start = now
+		l.total = 0
+	}
+
+	l.total += int64(n)
+	due := l.due()
+
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(due))
+}
+
+// due returns time when all bytes read since start are allowed by the rate.
+func (l *rateLimiter) due() time.Time {
+	return l.start.Add(time.Duration(float64(l.total) / float64(l.rate) * float64(time.Second)))
+}
+
+// slice returns size of one read not greater than n.
+func (l *rateLimiter) slice(n int) int {
+	if l == nil {
+		return n
+	}
+
+	s := int(l.rate / bwlimitSlices)
+	if s < 1 {
+		s = 1
+	}
+
+	if n < s {
+		return n
+	}
+
+	return s
+}
+
+// reader returns r limited by l.
+func (l *rateLimiter) reader(r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+
+	return &rateLimitedReader{r: r, limiter: l}
+}
+
+// readerAt returns r limited by l.
+func (l *rateLimiter) readerAt(r io.ReaderAt) io.ReaderAt {
+	if l == nil {
+		return r
+	}
+
+	return &rateLimitedReader{ra: r, limiter: l}
+}
+
+type rateLimitedReader struct {
+	r       io.Reader
+	ra      io.ReaderAt
+	limiter *rateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p[:r.limiter.slice(len(p))])
+	r.limiter.wait(n)
+
+	return n, err
+}
+
+// ReadAt reads len(p) bytes by slices, so p may be larger than allowed per slice.
+func (r *rateLimitedReader) ReadAt(p []byte, off int64) (int, error) {
+	var read int
+
+	for read < len(p) {
+		n, err := r.ra.ReadAt(p[read:read+r.limiter.slice(len(p)-read)], off+int64(read))
+		r.limiter.wait(n)
+		read += n
+
+		if err != nil {
+			return read, err
+		}
+	}
+
+	return read, nil
+}
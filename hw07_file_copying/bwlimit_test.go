@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected int64
+	}{
+		{in: "100", expected: 100},
+		{in: "100B/s", expected: 100},
+		{in: "10MiB/s", expected: 10 << 20},
+		{in: "10M", expected: 10 << 20},
+		{in: "500k", expected: 500 << 10},
+		{in: "2KB/s", expected: 2000},
+		{in: "1.5GB/s", expected: 1500000000},
+		{in: "1 GiB", expected: 1 << 30},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseBandwidth(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error in ParseBandwidth: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Fatalf("unexpected bandwidth: %d, expected: %d", got, tt.expected)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "fast", "10XB/s", "0", "-1M"} {
+		in := in
+		t.Run("Invalid/"+in, func(t *testing.T) {
+			_, err := ParseBandwidth(in)
+			if !errors.Is(err, ErrInvalidBandwidth) {
+				t.Fatalf(
+					"unexpected error in ParseBandwidth: %v, expected: %v",
+					err, ErrInvalidBandwidth,
+				)
+			}
+		})
+	}
+}
+
+func TestCopyBandwidthLimit(t *testing.T) {
+	expected, err := ioutil.ReadFile("./testdata/out_offset0_limit0.txt")
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	// 6617 bytes of input at 20 KiB/s take about 0.32s.
+	const (
+		rate       = 20 << 10
+		minElapsed = 250 * time.Millisecond
+	)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "Sequential", opts: []Option{WithSparse(SparseNever)}},
+		{name: "Sparse", opts: []Option{WithSparse(SparseAlways)}},
+		{name: "Parallel", opts: []Option{WithJobs(4)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			to := tempFile(t, nil)
+			start := time.Now()
+
+			if err := Copy("./testdata/input.txt", to, 0, 0, append(tt.opts, WithBandwidthLimit(rate))...); err != nil {
+				t.Fatalf("unexpected error in Copy: %v", err)
+			}
+
+			if elapsed := time.Since(start); elapsed < minElapsed {
+				t.Fatalf("copying is too fast: %v, expected at least: %v", elapsed, minElapsed)
+			}
+
+			requireFileContent(t, to, expected)
+		})
+	}
+
+	t.Run("Stdin", func(t *testing.T) {
+		withStdin(t, expected)
+		to := tempFile(t, nil)
+		start := time.Now()
+
+		if err := Copy("-", to, 0, 0, WithBandwidthLimit(rate)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < minElapsed {
+			t.Fatalf("copying is too fast: %v, expected at least: %v", elapsed, minElapsed)
+		}
+
+		requireFileContent(t, to, expected)
+	})
+}
@@ -3,12 +3,12 @@ package main
 import (
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/vbauerster/mpb"
-	"github.com/vbauerster/mpb/decor"
 )
 
 var (
@@ -17,73 +17,310 @@ var (
 )
 
 const (
-	progressBarWidth   = 64
-	oneHundredPercents = 100
+	// copyChunkSize is count of bytes copied between progress bar updates.
+	copyChunkSize = 1 << 20
+	// copyBufferSize is size of buffer used when kernel can't copy data itself.
+	copyBufferSize = 128 << 10
+	// checkpointSize is count of bytes copied between syncs in resume mode.
+	checkpointSize = 64 << 20
 )
 
+// unknownSize is a size to copy from sources which size can't be determined.
+const unknownSize = -1
+
 // Copy copies limit bytes from fromPath to toPath with offset.
-func Copy(fromPath string, toPath string, offset, limit int64) error {
-	srcFile, err := os.Open(fromPath)
+// Sources of unknown size, like pipes, devices or standard input
+// (fromPath "-"), are copied as a stream. Directories are copied
+// only in recursive mode. Data is written into a temporary file, which
+// replaces toPath only after successful copying, except resume mode,
+// where toPath is written in place.
+func Copy(fromPath string, toPath string, offset, limit int64, opts ...Option) error {
+	cfg := newConfig(opts)
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	if cfg.recursive && fromPath != stdinPath {
+		if info, err := os.Stat(fromPath); err == nil && info.IsDir() {
+			return copyTree(fromPath, toPath, offset, limit, cfg)
+		}
+	}
+
+	container := newContainer(cfg)
+
+	defer container.Wait()
+
+	sum, err := copyFile(container, nil, fromPath, toPath, offset, limit, cfg)
+	if err != nil || sum == nil {
+		return err
+	}
+
+	return writeChecksumFile(cfg.checksumOut, []checksumLine{{sum: sum, name: filepath.Base(toPath)}})
+}
+
+// copyFile copies limit bytes from fromPath to toPath with offset and shows
+// its progress in container. If total is not nil, the file is a part of a tree,
+// so total is incremented too and file bar is removed on complete.
+// It returns digest of copied data if it is required by cfg, else nil.
+func copyFile(
+	container *mpb.Progress,
+	total *mpb.Bar,
+	fromPath, toPath string,
+	offset, limit int64,
+	cfg config,
+) (sum []byte, err error) {
+	srcFile, err := openSource(fromPath)
 	if err != nil {
-		return fmt.Errorf("can't open file %s: %w", fromPath, err)
+		return nil, err
 	}
 
 	defer srcFile.Close()
 
 	sizeToCopy, err := getSizeToCopy(srcFile, offset, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	name := path.Base(fromPath)
+	if sizeToCopy == unknownSize && cfg.resume {
+		return nil, ErrResumeUnsupported
+	}
 
-	container := mpb.New(mpb.WithWidth(progressBarWidth))
+	if err := checkSameFile(srcFile, toPath); err != nil {
+		return nil, err
+	}
 
-	bar := container.AddBar(sizeToCopy,
-		mpb.BarStyle("[=>-|"),
-		mpb.PrependDecorators(
-			decor.CountersKibiByte("% .2f / % .2f "),
-			decor.OnComplete(decor.Name(name, decor.WC{W: len(name), C: decor.DextraSpace}), "done!"),
-		),
-		mpb.AppendDecorators(decor.Percentage()),
-	)
+	if err := checkNoClobber(toPath, cfg); err != nil {
+		return nil, err
+	}
 
-	defer container.Wait()
+	dstPath, inPlace, err := resolveDestination(toPath)
+	if err != nil {
+		return nil, err
+	}
 
-	bufSize := sizeToCopy / oneHundredPercents
-	buf := make([]byte, bufSize)
+	if inPlace {
+		if cfg.verify {
+			return nil, ErrVerifyUnsupported
+		}
 
-	dstFile, err := os.Create(toPath)
+		// Devices and FIFOs can only be written sequentially.
+		cfg.jobs, cfg.sparse = 1, SparseNever
+	}
+
+	dstFile, copied, err := openDestination(srcFile, dstPath, inPlace, offset, sizeToCopy, cfg)
 	if err != nil {
-		return fmt.Errorf("can't create file %s: %w", toPath, err)
+		return nil, err
 	}
 
 	defer dstFile.Close()
 
-	for i := 1; i < oneHundredPercents; i++ {
-		if err := copyPortion(srcFile, dstFile, &buf, offset); err != nil {
+	committed := cfg.resume || inPlace
+
+	defer func() {
+		if !committed {
+			os.Remove(dstFile.Name())
+		}
+	}()
+
+	var (
+		bar      *mpb.Bar
+		progress progressBar
+	)
+
+	if total == nil {
+		bar = newProgressBar(container, sourceName(fromPath), sizeToCopy)
+		progress = bar
+	} else {
+		bar = newProgressBar(container, sourceName(fromPath), sizeToCopy, mpb.BarRemoveOnComplete())
+		progress = multiProgressBar{bar, total}
+	}
+
+	progress.IncrBy(int(copied))
+
+	if total == nil && cfg.progress == ProgressJSON {
+		stop := startJSONProgress(cfg.progressOutput(), bar, sourceName(fromPath), sizeToCopy)
+		defer func() { stop(err) }()
+	}
+
+	digest := newDigest(cfg)
+
+	switch {
+	case sizeToCopy == unknownSize:
+		err = copyStream(cfg.limiter.reader(srcFile), dstFile, offset, limit, progress, digest)
+	case cfg.jobs > 1:
+		err = copyRangeParallel(
+			cfg.limiter.readerAt(srcFile), dstFile, offset, sizeToCopy, cfg.jobs, cfg.sparse == SparseAlways, progress,
+		)
+		if err == nil && digest != nil {
+			// Chunks are copied out of order, so the source is hashed separately.
+			err = hashSection(digest, srcFile, offset, sizeToCopy)
+		}
+	default:
+		err = copyResumedRange(srcFile, dstFile, offset, copied, sizeToCopy, progress, digest, cfg)
+	}
+
+	if err != nil {
+		container.Abort(bar, total != nil)
+		return nil, err
+	}
+
+	completeProgressBar(bar)
+
+	if digest != nil {
+		if sum, err = verifyDigest(digest, dstFile.Name(), cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if !committed {
+		if err := commitTemp(dstFile, dstPath, cfg); err != nil {
+			return nil, err
+		}
+
+		committed = true
+	}
+
+	return sum, nil
+}
+
+// copyResumedRange copies sizeToCopy bytes from srcFile starting at offset
+// into dstFile which already contains copied bytes. If digest is not nil,
+// the already copied part of the source is hashed before copying the rest.
+func copyResumedRange(
+	srcFile, dstFile *os.File,
+	offset, copied, sizeToCopy int64,
+	bar progressBar,
+	digest hash.Hash,
+	cfg config,
+) error {
+	if digest != nil && copied > 0 {
+		if err := hashSection(digest, srcFile, offset, copied); err != nil {
 			return err
 		}
+	}
 
-		bar.IncrBy(int(bufSize))
-		offset += bufSize
+	if cfg.sparse == SparseNever {
+		return copyRange(srcFile, dstFile, offset+copied, sizeToCopy-copied, bar, digest, cfg)
 	}
 
-	lastBufSize := bufSize + sizeToCopy%oneHundredPercents
-	lastBuf := make([]byte, lastBufSize)
+	return copyRangeSparse(srcFile, dstFile, offset+copied, sizeToCopy-copied, bar, digest, cfg)
+}
 
-	if err := copyPortion(srcFile, dstFile, &lastBuf, offset); err != nil {
-		return err
+// openDestination opens file to write data for toPath into and returns
+// count of bytes which are already copied into it. In resume mode or
+// if toPath must be written in place it is toPath itself, else it is
+// a new temporary file.
+func openDestination(
+	srcFile io.ReaderAt,
+	toPath string,
+	inPlace bool,
+	offset, sizeToCopy int64,
+	cfg config,
+) (*os.File, int64, error) {
+	if inPlace {
+		dstFile, err := os.OpenFile(toPath, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, 0, fmt.Errorf("can't open file %s: %w", toPath, err)
+		}
+
+		return dstFile, 0, nil
+	}
+
+	if cfg.resume {
+		return openResumed(srcFile, toPath, offset, sizeToCopy, cfg.resumeCheck)
+	}
+
+	dstFile, err := createTemp(toPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return dstFile, 0, nil
+}
+
+// copyRange copies size bytes from srcFile starting at offset into dstFile
+// by chunks and increments bar after each chunk. If digest is not nil,
+// copied data is written into it too. In resume mode dstFile is synced
+// every checkpointSize bytes, so its size on disk is a checkpoint
+// to continue from.
+func copyRange(srcFile, dstFile *os.File, offset, size int64, bar progressBar, digest hash.Hash, cfg config) error {
+	var src io.Reader
+	var dst io.Writer
+
+	// Don't read the source by io.SectionReader unless the copy is limited
+	// or hashed: it hides *os.File from ReadFrom and disables the kernel fast path.
+	if cfg.limiter == nil && digest == nil {
+		// os.File.ReadFrom uses copy_file_range(2) only when it reads from
+		// *os.File, optionally limited by io.LimitReader as io.CopyN does,
+		// so the source file is seeked instead of read by io.SectionReader.
+		// If the kernel can't copy, ReadFrom falls back to io.Copy.
+		if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("can't seek file %s: %w", srcFile.Name(), err)
+		}
+
+		src, dst = srcFile, dstFile
+	} else {
+		// Limited or hashed data must pass through user space anyway.
+		src = cfg.limiter.reader(io.NewSectionReader(srcFile, offset, size))
+		if digest != nil {
+			src = io.TeeReader(src, digest)
+		}
+
+		dst = bufferedWriter{w: dstFile, buf: make([]byte, copyBufferSize)}
+	}
+
+	var uncommitted int64
+
+	for size > 0 {
+		chunk := int64(copyChunkSize)
+		if size < chunk {
+			chunk = size
+		}
+
+		written, err := io.CopyN(dst, src, chunk)
+		bar.IncrBy(int(written))
+
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return fmt.Errorf("can't copy from %s to %s: %w", srcFile.Name(), dstFile.Name(), err)
+		}
+
+		size -= written
+		uncommitted += written
+
+		if uncommitted >= checkpointSize || size == 0 {
+			if err := checkpoint(dstFile, cfg); err != nil {
+				return err
+			}
+
+			uncommitted = 0
+		}
 	}
 
-	bar.IncrBy(int(lastBufSize))
+	return nil
+}
+
+// checkpoint flushes dstFile to disk if resume mode is enabled.
+func checkpoint(dstFile *os.File, cfg config) error {
+	if !cfg.resume {
+		return nil
+	}
+
+	if err := dstFile.Sync(); err != nil {
+		return fmt.Errorf("can't sync file %s: %w", dstFile.Name(), err)
+	}
 
 	return nil
 }
 
 // getSizeToCopy returns count of bytes to copy according with offset and limit.
-// It returns ErrUnsupportedFile if file is dir or it has zero size,
-// ErrOffsetExceedsFileSize if offset exceeds file size.
+// It returns unknownSize if file is not regular or it has zero size,
+// ErrUnsupportedFile if file is dir or it is a device which may be endless
+// and limit is not specified, ErrOffsetExceedsFileSize if offset exceeds file size.
 func getSizeToCopy(srcFile *os.File, offset, limit int64) (int64, error) {
 	info, err := srcFile.Stat()
 	if err != nil {
@@ -94,8 +331,12 @@ func getSizeToCopy(srcFile *os.File, offset, limit int64) (int64, error) {
 		return 0, ErrUnsupportedFile
 	}
 
-	if info.Size() == 0 {
-		return 0, ErrUnsupportedFile
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		if info.Mode()&os.ModeDevice != 0 && srcFile != os.Stdin && limit == 0 {
+			return 0, ErrUnsupportedFile
+		}
+
+		return unknownSize, nil
 	}
 
 	if offset > info.Size() {
@@ -115,17 +356,18 @@ func getSizeToCopy(srcFile *os.File, offset, limit int64) (int64, error) {
 	return size, nil
 }
 
-// copyPortion copies len(buf) bytes from srcFile into dstFile.
-func copyPortion(srcFile io.ReaderAt, dstFile io.Writer, buf *[]byte, offset int64) error {
-	_, err := srcFile.ReadAt(*buf, offset)
-	if err != nil && errors.Is(err, io.EOF) {
-		return fmt.Errorf("can't read from file with offset: %w", err)
-	}
+// bufferedWriter writes data read by io.Copy through reusable buf.
+// It hides ReadFrom of *os.File, which allocates its own buffer
+// for readers it can't copy by the kernel.
+type bufferedWriter struct {
+	w   io.Writer
+	buf []byte
+}
 
-	_, err = dstFile.Write(*buf)
-	if err != nil {
-		return fmt.Errorf("can't write buffer to file: %w", err)
-	}
+func (b bufferedWriter) Write(p []byte) (int, error) {
+	return b.w.Write(p)
+}
 
-	return nil
+func (b bufferedWriter) ReadFrom(r io.Reader) (int64, error) {
+	return io.CopyBuffer(struct{ io.Writer }{b.w}, r, b.buf)
 }
@@ -0,0 +1,128 @@
+//go:build bench
+// +build bench
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vbauerster/mpb"
+)
+
+const benchFileSize = 256 << 20
+
+// go test -run=^$ -bench=. -benchmem -tags bench .
+func BenchmarkCopyRange(b *testing.B) {
+	dir, err := ioutil.TempDir("/tmp", "bench-copy-*")
+	if err != nil {
+		b.Fatalf("unexpected error in TempDir: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	from := filepath.Join(dir, "from.bin")
+	if err := ioutil.WriteFile(from, make([]byte, benchFileSize), 0o644); err != nil {
+		b.Fatalf("unexpected error in WriteFile: %v", err)
+	}
+
+	b.Run("Percents", func(b *testing.B) {
+		benchmarkCopy(b, dir, from, func(src, dst *os.File, bar *mpb.Bar) error {
+			return copyRangeByPercents(src, dst, 0, benchFileSize, bar)
+		})
+	})
+
+	b.Run("Chunks", func(b *testing.B) {
+		benchmarkCopy(b, dir, from, func(src, dst *os.File, bar *mpb.Bar) error {
+			return copyRange(src, dst, 0, benchFileSize, bar, nil, config{})
+		})
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		benchmarkCopy(b, dir, from, func(src, dst *os.File, bar *mpb.Bar) error {
+			return copyRangeParallel(src, dst, 0, benchFileSize, 4, false, bar)
+		})
+	})
+}
+
+func benchmarkCopy(b *testing.B, dir, from string, copyFn func(src, dst *os.File, bar *mpb.Bar) error) {
+	container := mpb.New(mpb.WithOutput(ioutil.Discard))
+	bar := container.AddBar(0)
+
+	defer container.Abort(bar, true)
+
+	b.SetBytes(benchFileSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+
+		src, err := os.Open(from)
+		if err != nil {
+			b.Fatalf("unexpected error in Open: %v", err)
+		}
+
+		dst, err := os.Create(filepath.Join(dir, "to.bin"))
+		if err != nil {
+			b.Fatalf("unexpected error in Create: %v", err)
+		}
+
+		b.StartTimer()
+
+		if err := copyFn(src, dst, bar); err != nil {
+			b.Fatalf("unexpected error in copy: %v", err)
+		}
+
+		b.StopTimer()
+
+		src.Close()
+		dst.Close()
+
+		b.StartTimer()
+	}
+}
+
+// copyRangeByPercents is the previous implementation of copyRange, which
+// copies data by one hundred portions through ReadAt and Write.
+func copyRangeByPercents(srcFile io.ReaderAt, dstFile io.Writer, offset, size int64, bar *mpb.Bar) error {
+	const oneHundredPercents = 100
+
+	bufSize := size / oneHundredPercents
+	buf := make([]byte, bufSize)
+
+	for i := 1; i < oneHundredPercents; i++ {
+		if err := copyPortion(srcFile, dstFile, buf, offset); err != nil {
+			return err
+		}
+
+		bar.IncrBy(int(bufSize))
+		offset += bufSize
+	}
+
+	lastBuf := make([]byte, bufSize+size%oneHundredPercents)
+
+	if err := copyPortion(srcFile, dstFile, lastBuf, offset); err != nil {
+		return err
+	}
+
+	bar.IncrBy(len(lastBuf))
+
+	return nil
+}
+
+func copyPortion(srcFile io.ReaderAt, dstFile io.Writer, buf []byte, offset int64) error {
+	if _, err := srcFile.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("can't read from file with offset: %w", err)
+	}
+
+	if _, err := dstFile.Write(buf); err != nil {
+		return fmt.Errorf("can't write buffer to file: %w", err)
+	}
+
+	return nil
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -40,4 +48,362 @@ func TestCopy(t *testing.T) {
 			)
 		}
 	})
+
+	t.Run("OffsetEqualsFileSize", func(t *testing.T) {
+		to := tempFile(t, nil)
+
+		if err := Copy("./testdata/input_size2.txt", to, 2, 0); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, []byte{})
+	})
+
+	t.Run("LargeFile", func(t *testing.T) {
+		content := bytes.Repeat([]byte("0123456789abcdef"), 3*copyChunkSize/16+5)
+		from := tempFile(t, content)
+		to := tempFile(t, nil)
+
+		if err := Copy(from, to, 7, int64(len(content))); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, content[7:])
+	})
+}
+
+func TestCopyResume(t *testing.T) {
+	expected, err := ioutil.ReadFile("./testdata/out_offset100_limit1000.txt")
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	t.Run("PartialDestination", func(t *testing.T) {
+		to := tempFile(t, expected[:300])
+
+		if err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+	})
+
+	t.Run("MissingDestination", func(t *testing.T) {
+		to := tempFile(t, nil)
+		os.Remove(to)
+
+		if err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+	})
+
+	t.Run("CompletedDestination", func(t *testing.T) {
+		to := tempFile(t, expected)
+
+		if err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+	})
+
+	t.Run("DestinationTooLarge", func(t *testing.T) {
+		to := tempFile(t, append(expected, 'x'))
+
+		err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64))
+		if err != ErrDestinationTooLarge {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrDestinationTooLarge,
+			)
+		}
+	})
+
+	t.Run("DestinationMismatch", func(t *testing.T) {
+		partial := append([]byte{}, expected[:300]...)
+		partial[299]++
+		to := tempFile(t, partial)
+
+		err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64))
+		if err != ErrDestinationMismatch {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrDestinationMismatch,
+			)
+		}
+	})
+
+	t.Run("DestinationMismatch/CheckDisabled", func(t *testing.T) {
+		partial := append([]byte{}, expected[:300]...)
+		partial[299]++
+		to := tempFile(t, partial)
+
+		if err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(0)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		got, err := ioutil.ReadFile(to)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadFile: %v", err)
+		}
+
+		if !bytes.Equal(got[300:], expected[300:]) {
+			t.Fatalf("unexpected content of resumed part of %s", to)
+		}
+	})
+}
+
+// tempFile creates file with content in temporary directory
+// which is removed after the test.
+func tempFile(t *testing.T, content []byte) string {
+	t.Helper()
+
+	name := filepath.Join(tempDir(t), "out.txt")
+
+	if err := ioutil.WriteFile(name, content, 0o644); err != nil {
+		t.Fatalf("unexpected error in WriteFile: %v", err)
+	}
+
+	return name
+}
+
+func requireFileContent(t *testing.T, name string, expected []byte) {
+	t.Helper()
+
+	got, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("unexpected content of %s", name)
+	}
+}
+
+func TestCopyStream(t *testing.T) {
+	t.Run("Device", func(t *testing.T) {
+		to := tempFile(t, nil)
+
+		if err := Copy("/dev/urandom", to, 3, 10); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		info, err := os.Stat(to)
+		if err != nil {
+			t.Fatalf("unexpected error in Stat: %v", err)
+		}
+
+		if info.Size() != 10 {
+			t.Fatalf("unexpected size of %s: %d, expected: %d", to, info.Size(), 10)
+		}
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		from := tempFile(t, nil)
+		to := tempFile(t, []byte("old content"))
+
+		if err := Copy(from, to, 0, 0); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, []byte{})
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		input, err := ioutil.ReadFile("./testdata/input.txt")
+		if err != nil {
+			t.Fatalf("unexpected error in ReadFile: %v", err)
+		}
+
+		expected, err := ioutil.ReadFile("./testdata/out_offset100_limit1000.txt")
+		if err != nil {
+			t.Fatalf("unexpected error in ReadFile: %v", err)
+		}
+
+		withStdin(t, input)
+		to := tempFile(t, nil)
+
+		if err := Copy("-", to, 100, 1000); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+	})
+
+	t.Run("Stdin/OffsetExceedsFileSize", func(t *testing.T) {
+		withStdin(t, []byte("12"))
+		to := tempFile(t, nil)
+
+		err := Copy("-", to, 3, 0)
+		if err != ErrOffsetExceedsFileSize {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrOffsetExceedsFileSize,
+			)
+		}
+	})
+
+	t.Run("Stdin/Resume", func(t *testing.T) {
+		withStdin(t, []byte("12"))
+		to := tempFile(t, nil)
+
+		err := Copy("-", to, 0, 0, WithResume(0))
+		if err != ErrResumeUnsupported {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrResumeUnsupported,
+			)
+		}
+	})
+}
+
+// withStdin replaces os.Stdin with a pipe filled with content
+// until the end of the test.
+func withStdin(t *testing.T, content []byte) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error in Pipe: %v", err)
+	}
+
+	go func() {
+		defer w.Close()
+		w.Write(content) //nolint:errcheck
+	}()
+
+	stdin := os.Stdin
+	os.Stdin = r
+
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+}
+
+func TestCopyVerify(t *testing.T) {
+	expected, err := ioutil.ReadFile("./testdata/out_offset100_limit1000.txt")
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	expectedSum := fmt.Sprintf("%x", sha256.Sum256(expected))
+
+	t.Run("ChecksumOut", func(t *testing.T) {
+		to := tempFile(t, nil)
+		sumFile := to + ".sha256"
+
+		err := Copy("./testdata/input.txt", to, 100, 1000, WithVerify(), WithChecksumOut(sumFile))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, expected)
+		requireFileContent(t, sumFile, []byte(expectedSum+"  out.txt\n"))
+	})
+
+	t.Run("ChecksumOut/Resume", func(t *testing.T) {
+		to := tempFile(t, expected[:300])
+		sumFile := to + ".sha256"
+
+		err := Copy("./testdata/input.txt", to, 100, 1000, WithResume(64), WithVerify(), WithChecksumOut(sumFile))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, sumFile, []byte(expectedSum+"  out.txt\n"))
+	})
+
+	t.Run("ChecksumOut/Stdin", func(t *testing.T) {
+		input, err := ioutil.ReadFile("./testdata/input.txt")
+		if err != nil {
+			t.Fatalf("unexpected error in ReadFile: %v", err)
+		}
+
+		withStdin(t, input)
+		to := tempFile(t, nil)
+		sumFile := to + ".sha256"
+
+		err = Copy("-", to, 100, 1000, WithVerify(), WithChecksumOut(sumFile))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, sumFile, []byte(expectedSum+"  out.txt\n"))
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		newHash, err := hashByName("MD5")
+		if err != nil {
+			t.Fatalf("unexpected error in hashByName: %v", err)
+		}
+
+		to := tempFile(t, nil)
+		sumFile := to + ".md5"
+
+		err = Copy("./testdata/input.txt", to, 100, 1000, WithHash(newHash), WithChecksumOut(sumFile))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, sumFile, []byte(fmt.Sprintf("%x  out.txt\n", md5.Sum(expected))))
+	})
+
+	t.Run("UnknownHash", func(t *testing.T) {
+		_, err := hashByName("crc64")
+		if !errors.Is(err, ErrUnknownHash) {
+			t.Fatalf(
+				"unexpected error in hashByName: %v, expected: %v",
+				err, ErrUnknownHash,
+			)
+		}
+	})
+
+	t.Run("VerificationFailed", func(t *testing.T) {
+		to := tempFile(t, expected[1:])
+
+		digest := sha256.New()
+		digest.Write(expected) //nolint:errcheck
+
+		_, err := verifyDigest(digest, to, config{verify: true})
+		if err != ErrVerificationFailed {
+			t.Fatalf(
+				"unexpected error in verifyDigest: %v, expected: %v",
+				err, ErrVerificationFailed,
+			)
+		}
+	})
+}
+
+// writesRecorder records sizes of writes.
+type writesRecorder struct {
+	bytes.Buffer
+	sizes []int
+}
+
+func (w *writesRecorder) Write(p []byte) (int, error) {
+	w.sizes = append(w.sizes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestBufferedWriter(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	dst := &writesRecorder{}
+
+	n, err := io.CopyN(bufferedWriter{w: dst, buf: make([]byte, 16)}, bytes.NewReader(content), 90)
+	if err != nil || n != 90 {
+		t.Fatalf("unexpected result of CopyN: %d, %v", n, err)
+	}
+
+	if !bytes.Equal(dst.Bytes(), content[:90]) {
+		t.Fatalf("unexpected content: %q", dst.Bytes())
+	}
+
+	for _, size := range dst.sizes {
+		if size > 16 {
+			t.Fatalf("unexpected write of %d bytes bypassing buffer", size)
+		}
+	}
 }
@@ -4,7 +4,7 @@ go 1.14
 
 require (
 	github.com/VividCortex/ewma v1.1.1 // indirect
-	github.com/mattn/go-isatty v0.0.12 // indirect
+	github.com/mattn/go-isatty v0.0.12
 	github.com/vbauerster/mpb v3.4.0+incompatible
 	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897 // indirect
 )
@@ -6,27 +6,83 @@ import (
 	"os"
 )
 
+const defaultResumeCheck = 64 * 1024
+
+const (
+	symlinksPreserve = "preserve"
+	symlinksFollow   = "follow"
+)
+
 var (
 	from, to      string
 	limit, offset int64
+	resume        bool
+	resumeCheck   int64
+	verify        bool
+	hashName      string
+	checksumOut   string
+	recursive     bool
+	symlinks      string
+	jobs          int
+	sparse        string
+	noClobber     bool
+	backup        bool
+	quiet         bool
+	progress      string
+	bwlimit       string
 )
 
 func init() {
-	flag.StringVar(&from, "from", "", "file to read from")
+	flag.StringVar(&from, "from", "", "file to read from, \"-\" for standard input")
 	flag.StringVar(&to, "to", "", "file to write to")
 	flag.Int64Var(&limit, "limit", 0, "limit of bytes to copy")
 	flag.Int64Var(&offset, "offset", 0, "offset in input file")
+	flag.BoolVar(&resume, "resume", false, "continue interrupted copy into existing file")
+	flag.Int64Var(&resumeCheck, "resume-check", defaultResumeCheck, "bytes at the end of existing file to verify on resume")
+	flag.BoolVar(&verify, "verify", false, "compare digests of source range and destination after copying")
+	flag.StringVar(&hashName, "hash", defaultHash, "hash algorithm for -verify and -checksum-out")
+	flag.StringVar(&checksumOut, "checksum-out", "", "file to write digest of copied data to")
+	flag.BoolVar(&recursive, "r", false, "copy directories recursively")
+	flag.StringVar(&symlinks, "symlinks", symlinksPreserve, "symlinks in directories: preserve or follow")
+	flag.IntVar(&jobs, "jobs", 1, "count of chunks of a file copied concurrently")
+	flag.StringVar(&sparse, "sparse", "auto", "keep destination sparse: auto, always or never")
+	flag.BoolVar(&noClobber, "no-clobber", false, "don't replace existing destination")
+	flag.BoolVar(&backup, "backup", false, "keep existing destination as DEST~ when replacing it")
+	flag.BoolVar(&quiet, "quiet", false, "don't report progress")
+	flag.StringVar(&progress, "progress", "auto", "progress output: auto, bar, json or none")
+	flag.StringVar(&bwlimit, "bwlimit", "", "maximum reading rate, e.g. 10MiB/s")
 }
 
 const helpText = `
 Usage: go-cp [OPTION]... -from SOURCE -to DEST
-Copy SOURCE file to DEST file.
+Copy SOURCE file to DEST file or SOURCE directory to DEST directory.
+DEST is replaced only after successful copying. If DEST is a symlink,
+its target is replaced. Devices and FIFOs are written in place.
 
 Options:
 - to 		(mandatory)	path of toination file
-- from 		(mandatory)	path of source file to copy
+- from 		(mandatory)	path of source file to copy, "-" for standard input
 - limit 	(optional)	maximum bytes to copy
 - offset 	(optional)	offset in source file
+- resume 	(optional)	continue interrupted copy into existing DEST, DEST is written in place
+- resume-check 	(optional)	bytes at the end of DEST to verify on resume, 0 disables check
+- verify 	(optional)	compare digests of copied SOURCE range and DEST
+- hash 		(optional)	hash algorithm: md5, sha1, sha256 (default), sha512
+- checksum-out 	(optional)	file to write digest of copied data to
+- r 		(optional)	copy directories recursively, DEST becomes a copy of SOURCE
+- symlinks 	(optional)	symlinks in directories: preserve (default) or follow
+- jobs 		(optional)	count of chunks of a file copied concurrently, can't be used with resume,
+ 		 		holes are kept only with sparse always, with verify or checksum-out
+ 		 		SOURCE range is read again after copying to hash it
+- sparse 	(optional)	auto (default) keeps holes of SOURCE, always also skips zero blocks,
+ 		 		never writes every byte
+- no-clobber 	(optional)	fail instead of replacing existing DEST
+- backup 	(optional)	keep existing DEST as DEST~ when replacing it
+- quiet 	(optional)	don't report progress
+- progress 	(optional)	auto (default) draws bar if stdout is a terminal, bar always draws it,
+ 		 		json writes JSON lines every second, the last one has "done": true
+ 		 		on success or "error" on failure, none reports nothing
+- bwlimit 	(optional)	maximum reading rate, e.g. 10MiB/s, 500K or 1GB/s
 
 Examples:
 
@@ -34,7 +90,31 @@ With minimum options
 	cp -from /tmp/from.txt -to /tmp/to.txt
 
 With maximum options
-	cp -from /tmp/from.txt -to /tmp/to.txt -offset 10 -limit 5`
+	cp -from /tmp/from.txt -to /tmp/to.txt -offset 10 -limit 5
+
+Copy from standard input
+	cat /tmp/from.txt | cp -from - -to /tmp/to.txt -offset 10 -limit 5
+
+Continue interrupted copy
+	cp -from /tmp/from.txt -to /tmp/to.txt -offset 10 -limit 5 -resume
+
+Verify copied data and save its digest
+	cp -from /tmp/from.txt -to /tmp/to.txt -verify -hash sha512 -checksum-out /tmp/to.txt.sha512
+
+Copy directory following symlinks
+	cp -r -symlinks follow -from /tmp/from -to /tmp/to
+
+Copy large file by 4 concurrent jobs
+	cp -jobs 4 -from /tmp/from.img -to /tmp/to.img
+
+Copy VM image making it as sparse as possible
+	cp -sparse always -from /tmp/from.img -to /tmp/to.img
+
+Report progress to script
+	cp -progress json -from /tmp/from.img -to /tmp/to.img | jq .eta
+
+Copy without saturating disk
+	cp -bwlimit 10MiB/s -from /tmp/from.img -to /tmp/to.img`
 
 func main() {
 	flag.Parse()
@@ -45,17 +125,100 @@ func main() {
 	}
 
 	if from == "" {
-		fmt.Println("Source file is missing. Please specify it with -from option.")
+		fmt.Fprintln(os.Stderr, "Source file is missing. Please specify it with -from option.")
 		os.Exit(1)
 	}
 
 	if to == "" {
-		fmt.Println("Destination file is missing. Please specify it with -to option.")
+		fmt.Fprintln(os.Stderr, "Destination file is missing. Please specify it with -to option.")
+		os.Exit(1)
+	}
+
+	opts, err := options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	if err := Copy(from, to, offset, limit); err != nil {
-		fmt.Println(err)
+	if err := Copy(from, to, offset, limit, opts...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// options returns Copy options according with command line flags.
+func options() ([]Option, error) {
+	var opts []Option
+
+	if resume {
+		opts = append(opts, WithResume(resumeCheck))
+	}
+
+	if verify {
+		opts = append(opts, WithVerify())
+	}
+
+	newHash, err := hashByName(hashName)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, WithHash(newHash))
+
+	if checksumOut != "" {
+		opts = append(opts, WithChecksumOut(checksumOut))
+	}
+
+	if recursive {
+		opts = append(opts, WithRecursive())
+	}
+
+	if jobs > 1 {
+		opts = append(opts, WithJobs(jobs))
+	}
+
+	if noClobber {
+		opts = append(opts, WithNoClobber())
+	}
+
+	if backup {
+		opts = append(opts, WithBackup())
+	}
+
+	if bwlimit != "" {
+		rate, err := ParseBandwidth(bwlimit)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, WithBandwidthLimit(rate))
+	}
+
+	progressMode, err := ParseProgressMode(progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiet {
+		progressMode = ProgressNone
+	}
+
+	opts = append(opts, WithProgress(progressMode))
+
+	sparseMode, err := ParseSparseMode(sparse)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, WithSparse(sparseMode))
+
+	switch symlinks {
+	case symlinksPreserve:
+	case symlinksFollow:
+		opts = append(opts, WithFollowSymlinks())
+	default:
+		return nil, fmt.Errorf("unknown symlinks mode %q, supported: %s, %s", symlinks, symlinksPreserve, symlinksFollow)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"hash"
+	"io"
+	"os"
+)
+
+// Option configures optional behaviour of Copy.
+type Option func(*config)
+
+type config struct {
+	resume      bool
+	resumeCheck int64
+	verify      bool
+	newHash     func() hash.Hash
+	checksumOut string
+
+	recursive      bool
+	followSymlinks bool
+
+	jobs   int
+	sparse SparseMode
+
+	noClobber bool
+	backup    bool
+
+	progress    ProgressMode
+	progressOut io.Writer
+
+	bwlimit int64
+	// limiter is shared by all copies made with the config.
+	limiter *rateLimiter
+}
+
+func newConfig(opts []Option) config {
+	var cfg config
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.bwlimit > 0 {
+		cfg.limiter = newRateLimiter(cfg.bwlimit)
+	}
+
+	return cfg
+}
+
+// progressOutput returns writer to report progress into, stdout by default.
+func (c config) progressOutput() io.Writer {
+	if c.progressOut == nil {
+		return os.Stdout
+	}
+
+	return c.progressOut
+}
+
+// validate returns error if options of cfg can't be used together.
+func (c config) validate() error {
+	if c.jobs > 1 && c.resume {
+		return ErrParallelResume
+	}
+
+	if c.resume && (c.noClobber || c.backup) {
+		return ErrResumeOverwrite
+	}
+
+	return nil
+}
+
+// WithResume makes Copy continue an interrupted copy instead of
+// recreating the destination file. The destination is accepted only
+// if it is not larger than the range to copy and its last checkSize
+// bytes match the source, zero checkSize disables the tail check.
+func WithResume(checkSize int64) Option {
+	return func(c *config) {
+		c.resume = true
+		c.resumeCheck = checkSize
+	}
+}
+
+// WithVerify makes Copy compute digest of the source range while copying
+// and compare it with digest of the destination file after copying.
+func WithVerify() Option {
+	return func(c *config) {
+		c.verify = true
+	}
+}
+
+// WithHash sets hash algorithm used for verification and checksum file,
+// SHA-256 is used by default.
+func WithHash(newHash func() hash.Hash) Option {
+	return func(c *config) {
+		c.newHash = newHash
+	}
+}
+
+// WithChecksumOut makes Copy write digest of the copied range into file name.
+func WithChecksumOut(name string) Option {
+	return func(c *config) {
+		c.checksumOut = name
+	}
+}
+
+// WithRecursive makes Copy copy directories with their content.
+func WithRecursive() Option {
+	return func(c *config) {
+		c.recursive = true
+	}
+}
+
+// WithFollowSymlinks makes Copy copy targets of symlinks found in directories
+// instead of recreating the symlinks.
+func WithFollowSymlinks() Option {
+	return func(c *config) {
+		c.followSymlinks = true
+	}
+}
+
+// WithJobs makes Copy copy chunks of a file by jobs goroutines concurrently.
+// Parallel copy can't be combined with resume.
+func WithJobs(jobs int) Option {
+	return func(c *config) {
+		c.jobs = jobs
+	}
+}
+
+// WithSparse sets how Copy handles holes and zero blocks of source,
+// SparseAuto is used by default. In parallel mode only SparseAlways
+// has effect.
+func WithSparse(mode SparseMode) Option {
+	return func(c *config) {
+		c.sparse = mode
+	}
+}
+
+// WithNoClobber makes Copy fail with ErrDestinationExists instead of
+// replacing existing destination.
+func WithNoClobber() Option {
+	return func(c *config) {
+		c.noClobber = true
+	}
+}
+
+// WithBackup makes Copy keep existing destination as a hard link
+// with "~" appended to its name when replacing it.
+func WithBackup() Option {
+	return func(c *config) {
+		c.backup = true
+	}
+}
+
+// WithProgress sets how Copy reports progress, ProgressAuto is used by default.
+func WithProgress(mode ProgressMode) Option {
+	return func(c *config) {
+		c.progress = mode
+	}
+}
+
+// WithProgressOutput sets writer to report progress into instead of stdout.
+func WithProgressOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.progressOut = w
+	}
+}
+
+// WithBandwidthLimit limits reading of source to rate bytes per second.
+// The kernel fast path is not used with the limit.
+func WithBandwidthLimit(rate int64) Option {
+	return func(c *config) {
+		c.bwlimit = rate
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// parallelChunkSize is count of bytes copied by one job at once in parallel mode.
+const parallelChunkSize = 8 << 20
+
+var ErrParallelResume = errors.New("parallel copy can't be resumed")
+
+// chunk is a part of source range to copy in parallel mode.
+type chunk struct {
+	srcOffset, dstOffset, size int64
+}
+
+// copyRangeParallel copies size bytes from srcFile starting at offset into
+// the beginning of dstFile by jobs goroutines. The range is split into chunks,
+// which are read by ReadAt and written by WriteAt, so dstFile is truncated
+// to the whole size and its blocks are preallocated before copying. If
+// skipZeros is true, all-zero blocks are left as holes and nothing is
+// preallocated. The first error stops all jobs.
+func copyRangeParallel(srcFile io.ReaderAt, dstFile *os.File, offset, size int64, jobs int, skipZeros bool, bar progressBar) error {
+	if err := dstFile.Truncate(size); err != nil {
+		return fmt.Errorf("can't truncate file %s: %w", dstFile.Name(), err)
+	}
+
+	if !skipZeros {
+		if err := preallocate(dstFile, size); err != nil {
+			return fmt.Errorf("can't preallocate file %s: %w", dstFile.Name(), err)
+		}
+	}
+
+	chunks := make(chan chunk)
+	done := make(chan struct{})
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			buf := make([]byte, copyBufferSize)
+
+			for c := range chunks {
+				if err := copyChunk(srcFile, dstFile, c, buf, skipZeros, bar); err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(done)
+					})
+
+					return
+				}
+			}
+		}()
+	}
+
+	splitIntoChunks(chunks, done, offset, size)
+	close(chunks)
+	wg.Wait()
+
+	return firstErr
+}
+
+// splitIntoChunks sends chunks of size bytes starting at offset into chunks
+// until all of them are sent or done is closed.
+func splitIntoChunks(chunks chan<- chunk, done <-chan struct{}, offset, size int64) {
+	for pos := int64(0); pos < size; pos += parallelChunkSize {
+		c := chunk{srcOffset: offset + pos, dstOffset: pos, size: parallelChunkSize}
+		if pos+c.size > size {
+			c.size = size - pos
+		}
+
+		select {
+		case chunks <- c:
+		case <-done:
+			return
+		}
+	}
+}
+
+// copyChunk copies chunk c from srcFile into dstFile through buf.
+// If skipZeros is true, all-zero blocks are not written.
+func copyChunk(srcFile io.ReaderAt, dstFile io.WriterAt, c chunk, buf []byte, skipZeros bool, bar progressBar) error {
+	for c.size > 0 {
+		part := buf
+		if int64(len(part)) > c.size {
+			part = part[:c.size]
+		}
+
+		n, err := srcFile.ReadAt(part, c.srcOffset)
+		if n < len(part) {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return fmt.Errorf("can't read chunk at %d: %w", c.srcOffset, err)
+		}
+
+		if skipZeros {
+			err = writeNonZeroBlocks(dstFile, part, c.dstOffset)
+		} else {
+			_, err = dstFile.WriteAt(part, c.dstOffset)
+		}
+
+		if err != nil {
+			return fmt.Errorf("can't write chunk at %d: %w", c.dstOffset, err)
+		}
+
+		bar.IncrBy(n)
+
+		c.srcOffset += int64(n)
+		c.dstOffset += int64(n)
+		c.size -= int64(n)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCopyParallel(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), (3*parallelChunkSize+100)/16)
+	from := tempFile(t, content)
+
+	t.Run("Jobs", func(t *testing.T) {
+		for _, jobs := range []int{2, 4, 16} {
+			to := tempFile(t, []byte("old content which is longer than nothing"))
+
+			if err := Copy(from, to, 13, int64(len(content)), WithJobs(jobs)); err != nil {
+				t.Fatalf("unexpected error in Copy with %d jobs: %v", jobs, err)
+			}
+
+			requireFileContent(t, to, content[13:])
+		}
+	})
+
+	t.Run("EmptyRange", func(t *testing.T) {
+		to := tempFile(t, []byte("old content"))
+
+		if err := Copy(from, to, int64(len(content)), 0, WithJobs(4)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, []byte{})
+	})
+
+	t.Run("EmptySource", func(t *testing.T) {
+		empty := tempFile(t, nil)
+		to := tempFile(t, []byte("old content"))
+
+		if err := Copy(empty, to, 0, 0, WithJobs(4)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, []byte{})
+	})
+
+	t.Run("ChecksumOut", func(t *testing.T) {
+		to := tempFile(t, nil)
+		sumFile := to + ".sha256"
+
+		if err := Copy(from, to, 0, 1000, WithJobs(4), WithVerify(), WithChecksumOut(sumFile)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, sumFile, []byte(fmt.Sprintf("%x  out.txt\n", sha256.Sum256(content[:1000]))))
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		to := tempFile(t, nil)
+
+		err := Copy(from, to, 0, 0, WithJobs(4), WithResume(0))
+		if err != ErrParallelResume {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrParallelResume,
+			)
+		}
+	})
+}
+
+func TestCopyChunk(t *testing.T) {
+	t.Run("ShortSource", func(t *testing.T) {
+		src := bytes.NewReader([]byte("0123456789"))
+		dst := &bytesWriterAt{}
+
+		err := copyChunk(src, dst, chunk{srcOffset: 5, size: 10}, make([]byte, 4), false, nopProgressBar{})
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf(
+				"unexpected error in copyChunk: %v, expected: %v",
+				err, io.ErrUnexpectedEOF,
+			)
+		}
+	})
+
+	t.Run("Offsets", func(t *testing.T) {
+		src := bytes.NewReader([]byte("0123456789"))
+		dst := &bytesWriterAt{buf: []byte("..........")}
+
+		if err := copyChunk(src, dst, chunk{srcOffset: 2, dstOffset: 5, size: 5}, make([]byte, 2), false, nopProgressBar{}); err != nil {
+			t.Fatalf("unexpected error in copyChunk: %v", err)
+		}
+
+		if string(dst.buf) != ".....23456" {
+			t.Fatalf("unexpected destination: %s, expected: %s", dst.buf, ".....23456")
+		}
+	})
+}
+
+type bytesWriterAt struct {
+	buf []byte
+}
+
+func (w *bytesWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(w.buf) {
+		w.buf = append(w.buf, make([]byte, end-len(w.buf))...)
+	}
+
+	return copy(w.buf[off:], p), nil
+}
+
+type nopProgressBar struct{}
+
+func (nopProgressBar) IncrBy(int, ...time.Duration) {}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// preallocate reserves disk blocks for the first size bytes of f, so running
+// out of space is reported before copying. Filesystems without fallocate(2)
+// support are left as is. Empty size is skipped, because fallocate(2)
+// rejects it.
+func preallocate(f *os.File, size int64) error {
+	if size <= 0 {
+		return nil
+	}
+
+	err := syscall.Fallocate(int(f.Fd()), 0, 0, size)
+	if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENOSYS) {
+		return nil
+	}
+
+	return err
+}
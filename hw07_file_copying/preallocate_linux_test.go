@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPreallocate(t *testing.T) {
+	name := tempFile(t, nil)
+
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("unexpected error in OpenFile: %v", err)
+	}
+	defer f.Close()
+
+	if err := preallocate(f, holeSize); err != nil {
+		t.Fatalf("unexpected error in preallocate: %v", err)
+	}
+
+	requireSparse(t, name, false)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+)
+
+const (
+	progressBarWidth = 64
+	// jsonProgressInterval is a period of writing JSON progress lines.
+	jsonProgressInterval = time.Second
+)
+
+// ProgressMode defines how Copy reports progress.
+type ProgressMode int
+
+const (
+	// ProgressAuto draws progress bars if output is a terminal, else reports nothing.
+	ProgressAuto ProgressMode = iota
+	// ProgressBar always draws progress bars.
+	ProgressBar
+	// ProgressJSON periodically writes progress as JSON lines.
+	ProgressJSON
+	// ProgressNone reports nothing.
+	ProgressNone
+)
+
+var ErrUnknownProgressMode = errors.New("unknown progress mode")
+
+var progressModes = map[string]ProgressMode{
+	"auto": ProgressAuto,
+	"bar":  ProgressBar,
+	"json": ProgressJSON,
+	"none": ProgressNone,
+}
+
+// ParseProgressMode returns ProgressMode by its name: auto, bar, json or none.
+func ParseProgressMode(name string) (ProgressMode, error) {
+	mode, ok := progressModes[name]
+	if !ok {
+		return 0, fmt.Errorf("%w %q, supported: auto, bar, json, none", ErrUnknownProgressMode, name)
+	}
+
+	return mode, nil
+}
+
+// newContainer returns container for progress bars. Bars are drawn into
+// progress output in bar mode or in auto mode if the output is a terminal,
+// otherwise they only count progress.
+func newContainer(cfg config) *mpb.Progress {
+	out := cfg.progressOutput()
+
+	if cfg.progress != ProgressBar && !(cfg.progress == ProgressAuto && isTerminal(out)) {
+		out = ioutil.Discard
+	}
+
+	return mpb.New(mpb.WithWidth(progressBarWidth), mpb.WithOutput(out))
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// progressBar is a part of *mpb.Bar used to report progress of copying.
+type progressBar interface {
+	IncrBy(n int, wdd ...time.Duration)
+}
+
+// multiProgressBar reports the same progress into several bars.
+type multiProgressBar []progressBar
+
+func (m multiProgressBar) IncrBy(n int, wdd ...time.Duration) {
+	for _, bar := range m {
+		bar.IncrBy(n, wdd...)
+	}
+}
+
+// progressReader increments bar by count of bytes read from Reader.
+type progressReader struct {
+	io.Reader
+	bar progressBar
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.bar.IncrBy(n)
+	}
+
+	return n, err
+}
+
+// newProgressBar adds bar for copying total bytes of file name into container.
+// If total is unknown, bar shows a spinner with count of copied bytes
+// instead of percents. Both of them show average rate of copying.
+func newProgressBar(container *mpb.Progress, name string, total int64, opts ...mpb.BarOption) *mpb.Bar {
+	nameDecorator := decor.OnComplete(decor.Name(name, decor.WC{W: len(name), C: decor.DextraSpace}), "done!")
+
+	if total == unknownSize {
+		return container.AddSpinner(0, mpb.SpinnerOnLeft, append([]mpb.BarOption{
+			mpb.PrependDecorators(
+				byteCounter("% .2f "),
+				nameDecorator,
+			),
+			mpb.AppendDecorators(decor.AverageSpeed(decor.UnitKiB, " % .2f")),
+		}, opts...)...)
+	}
+
+	return container.AddBar(total, append([]mpb.BarOption{
+		mpb.BarStyle("[=>-|"),
+		mpb.PrependDecorators(
+			decor.CountersKibiByte("% .2f / % .2f "),
+			nameDecorator,
+		),
+		mpb.AppendDecorators(
+			decor.Percentage(),
+			decor.AverageSpeed(decor.UnitKiB, " % .2f"),
+		),
+	}, opts...)...)
+}
+
+// completeProgressBar marks bar as completed. It is required for bars
+// with unknown or zero total, which can't be completed by increments.
+func completeProgressBar(bar *mpb.Bar) {
+	bar.SetTotal(bar.Current(), true)
+}
+
+type byteCounterDecorator struct {
+	decor.WC
+	format string
+}
+
+// byteCounter returns decorator which shows count of processed bytes
+// with dynamic unit, e.g. "% .2f" gives "1.50 MiB".
+func byteCounter(format string, wcc ...decor.WC) decor.Decorator {
+	var wc decor.WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+
+	wc.Init()
+
+	return &byteCounterDecorator{WC: wc, format: format}
+}
+
+func (d *byteCounterDecorator) Decor(st *decor.Statistics) string {
+	return d.FormatMsg(fmt.Sprintf(d.format, decor.CounterKiB(st.Current)))
+}
+
+// jsonProgress is a line of progress in JSON mode.
+type jsonProgress struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+	// Total is omitted for sources of unknown size.
+	Total *int64 `json:"total,omitempty"`
+	// Rate is an average count of bytes copied per second.
+	Rate float64 `json:"rate"`
+	// ETA is an estimated count of seconds to complete copying,
+	// it is omitted if it can't be estimated.
+	ETA *float64 `json:"eta,omitempty"`
+	// Done is true for the last line of successful copying.
+	Done bool `json:"done"`
+	// Error is set in the last line of failed copying.
+	Error string `json:"error,omitempty"`
+}
+
+// startJSONProgress writes progress of bar copying total bytes of file name
+// into w every jsonProgressInterval. Returned function stops writing after
+// the last line, which reports result of copying err.
+func startJSONProgress(w io.Writer, bar *mpb.Bar, name string, total int64) (stop func(err error)) {
+	start := time.Now()
+	base := bar.Current()
+	enc := json.NewEncoder(w)
+
+	report := func(done bool, err error) {
+		line := jsonProgress{Name: name, Bytes: bar.Current(), Done: done}
+		if err != nil {
+			line.Error = err.Error()
+		}
+
+		if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+			line.Rate = float64(line.Bytes-base) / elapsed
+		}
+
+		if total != unknownSize {
+			line.Total = &total
+
+			if line.Rate > 0 {
+				eta := float64(total-line.Bytes) / line.Rate
+				line.ETA = &eta
+			}
+		}
+
+		enc.Encode(line) //nolint:errcheck
+	}
+
+	stopCh := make(chan error, 1)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(jsonProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				report(false, nil)
+			case err := <-stopCh:
+				report(err == nil, err)
+				return
+			}
+		}
+	}()
+
+	return func(err error) {
+		stopCh <- err
+		<-stopped
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyProgress(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		err := Copy("./testdata/input.txt", to, 100, 1000, WithProgress(ProgressJSON), WithProgressOutput(out))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		last := lastJSONProgress(t, out)
+
+		if last.Name != "input.txt" || last.Bytes != 1000 || last.Total == nil || *last.Total != 1000 || !last.Done {
+			t.Fatalf("unexpected last progress line: %s", out)
+		}
+	})
+
+	t.Run("JSON/Stdin", func(t *testing.T) {
+		withStdin(t, []byte("0123456789"))
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		if err := Copy("-", to, 0, 0, WithProgress(ProgressJSON), WithProgressOutput(out)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		last := lastJSONProgress(t, out)
+
+		if last.Name != "stdin" || last.Bytes != 10 || last.Total != nil || last.ETA != nil || !last.Done {
+			t.Fatalf("unexpected last progress line: %s", out)
+		}
+	})
+
+	t.Run("JSON/Failed", func(t *testing.T) {
+		withStdin(t, []byte("12"))
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		err := Copy("-", to, 3, 0, WithProgress(ProgressJSON), WithProgressOutput(out))
+		if !errors.Is(err, ErrOffsetExceedsFileSize) {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrOffsetExceedsFileSize,
+			)
+		}
+
+		last := lastJSONProgress(t, out)
+
+		if last.Done || last.Error != ErrOffsetExceedsFileSize.Error() {
+			t.Fatalf("unexpected last progress line: %s", out)
+		}
+	})
+
+	t.Run("JSON/Tree", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		to := filepath.Join(tempDir(t), "copy")
+
+		err := Copy("./testdata", to, 0, 0, WithRecursive(), WithProgress(ProgressJSON), WithProgressOutput(out))
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		last := lastJSONProgress(t, out)
+
+		if last.Name != "total" || last.Total == nil || last.Bytes != *last.Total || !last.Done {
+			t.Fatalf("unexpected last progress line: %s", out)
+		}
+	})
+
+	t.Run("Bar", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		if err := Copy("./testdata/input.txt", to, 0, 0, WithProgress(ProgressBar), WithProgressOutput(out)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if !strings.Contains(out.String(), "100 %") {
+			t.Fatalf("unexpected progress output: %q", out)
+		}
+	})
+
+	t.Run("AutoWithoutTerminal", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		if err := Copy("./testdata/input.txt", to, 0, 0, WithProgressOutput(out)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if out.Len() != 0 {
+			t.Fatalf("unexpected progress output: %q", out)
+		}
+	})
+
+	t.Run("None", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		to := tempFile(t, nil)
+
+		if err := Copy("./testdata/input.txt", to, 0, 0, WithProgress(ProgressNone), WithProgressOutput(out)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		if out.Len() != 0 {
+			t.Fatalf("unexpected progress output: %q", out)
+		}
+	})
+
+	t.Run("UnknownMode", func(t *testing.T) {
+		_, err := ParseProgressMode("xml")
+		if !errors.Is(err, ErrUnknownProgressMode) {
+			t.Fatalf(
+				"unexpected error in ParseProgressMode: %v, expected: %v",
+				err, ErrUnknownProgressMode,
+			)
+		}
+	})
+}
+
+func lastJSONProgress(t *testing.T, out *bytes.Buffer) jsonProgress {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+	var last jsonProgress
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("unexpected error in Unmarshal: %v", err)
+	}
+
+	return last
+}
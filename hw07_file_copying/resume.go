@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+var (
+	ErrDestinationTooLarge = errors.New("destination is larger than range to copy")
+	ErrDestinationMismatch = errors.New("destination doesn't match source")
+)
+
+// openResumed opens toPath to continue copying into it and returns
+// count of bytes already copied. The file is created if it doesn't exist.
+// It returns ErrDestinationTooLarge if toPath contains more than sizeToCopy
+// bytes, ErrDestinationMismatch if the tail of toPath differs from the source.
+func openResumed(srcFile io.ReaderAt, toPath string, offset, sizeToCopy, checkSize int64) (*os.File, int64, error) {
+	dstFile, err := os.OpenFile(toPath, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, 0, fmt.Errorf("can't open file %s: %w", toPath, err)
+	}
+
+	copied, err := checkResumed(srcFile, dstFile, offset, sizeToCopy, checkSize)
+	if err != nil {
+		dstFile.Close()
+		return nil, 0, err
+	}
+
+	if _, err := dstFile.Seek(copied, io.SeekStart); err != nil {
+		dstFile.Close()
+		return nil, 0, fmt.Errorf("can't seek file %s: %w", toPath, err)
+	}
+
+	return dstFile, copied, nil
+}
+
+// checkResumed returns size of dstFile after checking that it is a valid
+// prefix of the source range starting at offset.
+func checkResumed(srcFile io.ReaderAt, dstFile *os.File, offset, sizeToCopy, checkSize int64) (int64, error) {
+	info, err := dstFile.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("can't get file info for %s: %w", dstFile.Name(), err)
+	}
+
+	copied := info.Size()
+
+	if copied > sizeToCopy {
+		return 0, ErrDestinationTooLarge
+	}
+
+	if checkSize > copied {
+		checkSize = copied
+	}
+
+	if checkSize <= 0 {
+		return copied, nil
+	}
+
+	srcSum, err := checksum(io.NewSectionReader(srcFile, offset+copied-checkSize, checkSize))
+	if err != nil {
+		return 0, fmt.Errorf("can't read source tail: %w", err)
+	}
+
+	dstSum, err := checksum(io.NewSectionReader(dstFile, copied-checkSize, checkSize))
+	if err != nil {
+		return 0, fmt.Errorf("can't read destination tail: %w", err)
+	}
+
+	if srcSum != dstSum {
+		return 0, ErrDestinationMismatch
+	}
+
+	return copied, nil
+}
+
+// checksum returns CRC-32 checksum of all data from r.
+func checksum(r io.Reader) (uint32, error) {
+	h := crc32.NewIEEE()
+
+	if _, err := io.Copy(h, r); err != nil {
+		return 0, err
+	}
+
+	return h.Sum32(), nil
+}
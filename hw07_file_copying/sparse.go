@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// SparseMode defines how Copy handles holes and zero blocks of source.
+type SparseMode int
+
+const (
+	// SparseAuto keeps holes of source found by SEEK_DATA and SEEK_HOLE.
+	// If the system can't find holes, all-zero blocks are skipped instead.
+	SparseAuto SparseMode = iota
+	// SparseNever writes every byte of source into destination.
+	SparseNever
+	// SparseAlways skips all-zero blocks even in data regions of source.
+	SparseAlways
+)
+
+// sparseBlockSize is a size of blocks checked for zeros,
+// it is a common size of file system block.
+const sparseBlockSize = 4096
+
+var (
+	ErrUnknownSparseMode = errors.New("unknown sparse mode")
+
+	// errHolesUnsupported means that holes of file can't be found.
+	errHolesUnsupported = errors.New("holes detection is unsupported")
+)
+
+var zeroBlock = make([]byte, sparseBlockSize)
+
+var sparseModes = map[string]SparseMode{
+	"auto":   SparseAuto,
+	"never":  SparseNever,
+	"always": SparseAlways,
+}
+
+// ParseSparseMode returns SparseMode by its name: auto, never or always.
+func ParseSparseMode(name string) (SparseMode, error) {
+	mode, ok := sparseModes[name]
+	if !ok {
+		return 0, fmt.Errorf("%w %q, supported: auto, never, always", ErrUnknownSparseMode, name)
+	}
+
+	return mode, nil
+}
+
+// segment is a region of file which contains data.
+type segment struct {
+	offset, size int64
+}
+
+// copyRangeSparse copies size bytes from srcFile starting at offset into dstFile
+// at its current position keeping destination sparse according with cfg.sparse.
+// Holes are created by skipping regions of destination and truncating it
+// to the full size at the end. Skipped regions are counted by bar and hashed
+// into digest as zeros.
+func copyRangeSparse(srcFile, dstFile *os.File, offset, size int64, bar progressBar, digest hash.Hash, cfg config) error {
+	dstStart, err := dstFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("can't seek file %s: %w", dstFile.Name(), err)
+	}
+
+	skipZeros := cfg.sparse == SparseAlways
+
+	segments, err := dataSegments(srcFile, offset, size)
+	if errors.Is(err, errHolesUnsupported) {
+		segments = []segment{{offset: offset, size: size}}
+		skipZeros = true
+	} else if err != nil {
+		return err
+	}
+
+	pos := offset
+
+	for _, seg := range segments {
+		if err := skipHole(seg.offset-pos, bar, digest); err != nil {
+			return err
+		}
+
+		dstOffset := dstStart + seg.offset - offset
+
+		if skipZeros {
+			err = copySkippingZeros(cfg.limiter.readerAt(srcFile), dstFile, seg.offset, dstOffset, seg.size, bar, digest, cfg)
+		} else {
+			if _, err := dstFile.Seek(dstOffset, io.SeekStart); err != nil {
+				return fmt.Errorf("can't seek file %s: %w", dstFile.Name(), err)
+			}
+
+			err = copyRange(srcFile, dstFile, seg.offset, seg.size, bar, digest, cfg)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		pos = seg.offset + seg.size
+	}
+
+	if err := skipHole(offset+size-pos, bar, digest); err != nil {
+		return err
+	}
+
+	if err := dstFile.Truncate(dstStart + size); err != nil {
+		return fmt.Errorf("can't truncate file %s: %w", dstFile.Name(), err)
+	}
+
+	return nil
+}
+
+// skipHole counts hole of size bytes in bar and digest.
+func skipHole(size int64, bar progressBar, digest hash.Hash) error {
+	if size <= 0 {
+		return nil
+	}
+
+	bar.IncrBy(int(size))
+
+	if digest == nil {
+		return nil
+	}
+
+	if _, err := io.CopyN(digest, zeroReader{}, size); err != nil {
+		return fmt.Errorf("can't hash hole: %w", err)
+	}
+
+	return nil
+}
+
+// copySkippingZeros copies size bytes from srcFile at srcOffset into dstFile
+// at dstOffset without writing blocks which contain only zeros.
+func copySkippingZeros(
+	srcFile io.ReaderAt,
+	dstFile *os.File,
+	srcOffset, dstOffset, size int64,
+	bar progressBar,
+	digest hash.Hash,
+	cfg config,
+) error {
+	buf := make([]byte, copyBufferSize)
+
+	for size > 0 {
+		part := buf
+		if int64(len(part)) > size {
+			part = part[:size]
+		}
+
+		n, err := srcFile.ReadAt(part, srcOffset)
+		if n < len(part) {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return fmt.Errorf("can't read at %d: %w", srcOffset, err)
+		}
+
+		if digest != nil {
+			digest.Write(part) //nolint:errcheck
+		}
+
+		if err := writeNonZeroBlocks(dstFile, part, dstOffset); err != nil {
+			return err
+		}
+
+		bar.IncrBy(n)
+
+		srcOffset += int64(n)
+		dstOffset += int64(n)
+		size -= int64(n)
+	}
+
+	return checkpoint(dstFile, cfg)
+}
+
+// writeNonZeroBlocks writes p into dst at offset by blocks of sparseBlockSize
+// skipping blocks which contain only zeros.
+func writeNonZeroBlocks(dst io.WriterAt, p []byte, offset int64) error {
+	for start := 0; start < len(p); start += sparseBlockSize {
+		end := start + sparseBlockSize
+		if end > len(p) {
+			end = len(p)
+		}
+
+		block := p[start:end]
+		if isZero(block) {
+			continue
+		}
+
+		if _, err := dst.WriteAt(block, offset+int64(start)); err != nil {
+			return fmt.Errorf("can't write at %d: %w", offset+int64(start), err)
+		}
+	}
+
+	return nil
+}
+
+// isZero reports whether block of at most sparseBlockSize bytes contains only zeros.
+func isZero(block []byte) bool {
+	return bytes.Equal(block, zeroBlock[:len(block)])
+}
+
+// zeroReader is an endless reader of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	return len(p), nil
+}
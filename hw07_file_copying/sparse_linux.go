@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Whence values of lseek(2) to find data and holes of file on Linux.
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// dataSegments returns regions of f which contain data within size bytes
+// starting at offset. Regions between them are holes.
+func dataSegments(f *os.File, offset, size int64) ([]segment, error) {
+	var segments []segment
+
+	end := offset + size
+
+	for pos := offset; pos < end; {
+		dataStart, err := f.Seek(pos, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			break
+		}
+
+		if errors.Is(err, syscall.EINVAL) {
+			return nil, errHolesUnsupported
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("can't find data in file %s: %w", f.Name(), err)
+		}
+
+		if dataStart >= end {
+			break
+		}
+
+		holeStart, err := f.Seek(dataStart, seekHole)
+		if err != nil {
+			return nil, fmt.Errorf("can't find hole in file %s: %w", f.Name(), err)
+		}
+
+		if holeStart > end {
+			holeStart = end
+		}
+
+		segments = append(segments, segment{offset: dataStart, size: holeStart - dataStart})
+		pos = holeStart
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+const holeSize = 1 << 20
+
+func TestCopySparse(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), sparseBlockSize)
+	content := append(append(append(make([]byte, holeSize), data...), make([]byte, holeSize)...), data...)
+
+	t.Run("Auto", func(t *testing.T) {
+		from := tempSparseFile(t, data)
+		to := tempFile(t, nil)
+
+		if err := Copy(from, to, 0, 0); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, content)
+		requireSparse(t, to, true)
+	})
+
+	t.Run("Auto/Range", func(t *testing.T) {
+		from := tempSparseFile(t, data)
+		to := tempFile(t, nil)
+		sumFile := to + ".sha256"
+
+		err := Copy(from, to, holeSize/2, holeSize+sparseBlockSize+holeSize/2, WithChecksumOut(sumFile), WithVerify())
+		if err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		expected := content[holeSize/2 : 2*holeSize+sparseBlockSize]
+
+		requireFileContent(t, to, expected)
+		requireFileContent(t, sumFile, []byte(fmt.Sprintf("%x  out.txt\n", sha256.Sum256(expected))))
+		requireSparse(t, to, true)
+	})
+
+	t.Run("Never", func(t *testing.T) {
+		from := tempSparseFile(t, data)
+		to := tempFile(t, nil)
+
+		if err := Copy(from, to, 0, 0, WithSparse(SparseNever)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, content)
+		requireSparse(t, to, false)
+	})
+
+	t.Run("Always", func(t *testing.T) {
+		from := tempFile(t, content)
+		to := tempFile(t, nil)
+
+		if err := Copy(from, to, 0, 0, WithSparse(SparseAlways)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, content)
+		requireSparse(t, to, true)
+	})
+
+	t.Run("Always/Jobs", func(t *testing.T) {
+		from := tempFile(t, content)
+		to := tempFile(t, nil)
+
+		if err := Copy(from, to, 0, 0, WithSparse(SparseAlways), WithJobs(4)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, to, content)
+		requireSparse(t, to, true)
+	})
+
+	t.Run("UnknownMode", func(t *testing.T) {
+		_, err := ParseSparseMode("sometimes")
+		if !errors.Is(err, ErrUnknownSparseMode) {
+			t.Fatalf(
+				"unexpected error in ParseSparseMode: %v, expected: %v",
+				err, ErrUnknownSparseMode,
+			)
+		}
+	})
+}
+
+// tempSparseFile creates file which consists of hole, data, hole and data again.
+func tempSparseFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	name := tempFile(t, nil)
+
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("unexpected error in OpenFile: %v", err)
+	}
+
+	defer f.Close()
+
+	for _, offset := range []int64{holeSize, 2*holeSize + int64(len(data))} {
+		if _, err := f.WriteAt(data, offset); err != nil {
+			t.Fatalf("unexpected error in WriteAt: %v", err)
+		}
+	}
+
+	return name
+}
+
+// requireSparse checks that file name has less allocated space than its size
+// if sparse is true, else not less than its size.
+func requireSparse(t *testing.T, name string, sparse bool) {
+	t.Helper()
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("unexpected error in Stat: %v", err)
+	}
+
+	allocated := info.Sys().(*syscall.Stat_t).Blocks * 512
+
+	if sparse && allocated >= info.Size() {
+		t.Fatalf("file %s is not sparse: allocated %d of %d bytes", name, allocated, info.Size())
+	}
+
+	if !sparse && allocated < info.Size() {
+		t.Fatalf("file %s is sparse: allocated %d of %d bytes", name, allocated, info.Size())
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "os"
+
+// dataSegments returns errHolesUnsupported, because holes
+// are found only on Linux.
+func dataSegments(f *os.File, offset, size int64) ([]segment, error) {
+	return nil, errHolesUnsupported
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// stdinPath is a source path which means standard input.
+const stdinPath = "-"
+
+var ErrResumeUnsupported = errors.New("resume is unsupported for files of unknown size")
+
+// openSource opens fromPath for reading, stdinPath means standard input.
+func openSource(fromPath string) (*os.File, error) {
+	if fromPath == stdinPath {
+		return os.Stdin, nil
+	}
+
+	srcFile, err := os.Open(fromPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %s: %w", fromPath, err)
+	}
+
+	return srcFile, nil
+}
+
+// sourceName returns name of fromPath to show in progress bar.
+func sourceName(fromPath string) string {
+	if fromPath == stdinPath {
+		return "stdin"
+	}
+
+	return filepath.Base(fromPath)
+}
+
+// copyStream copies limit bytes from src into dst after skipping offset bytes.
+// Zero limit means copying until EOF. It is used for sources of unknown size,
+// so offset is skipped by reading and bytes are counted by bar.
+// If digest is not nil, copied data is written into it too.
+func copyStream(src io.Reader, dst io.Writer, offset, limit int64, bar progressBar, digest hash.Hash) error {
+	if offset > 0 {
+		_, err := io.CopyN(ioutil.Discard, src, offset)
+		if errors.Is(err, io.EOF) {
+			return ErrOffsetExceedsFileSize
+		}
+
+		if err != nil {
+			return fmt.Errorf("can't skip offset: %w", err)
+		}
+	}
+
+	if limit > 0 {
+		src = io.LimitReader(src, limit)
+	}
+
+	if digest != nil {
+		src = io.TeeReader(src, digest)
+	}
+
+	if _, err := io.Copy(dst, &progressReader{Reader: src, bar: bar}); err != nil {
+		return fmt.Errorf("can't copy stream: %w", err)
+	}
+
+	return nil
+}
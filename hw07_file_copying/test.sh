@@ -21,5 +21,19 @@ cmp out.txt testdata/out_offset100_limit1000.txt
 ./go-cp -from testdata/input.txt -to out.txt -offset 6000 -limit 1000
 cmp out.txt testdata/out_offset6000_limit1000.txt
 
-rm -f go-cp out.txt
+./go-cp -from - -to out.txt -offset 100 -limit 1000 < testdata/input.txt
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+head -c 300 testdata/out_offset100_limit1000.txt > out.txt
+./go-cp -from testdata/input.txt -to out.txt -offset 100 -limit 1000 -resume
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+./go-cp -from testdata/input.txt -to out.txt -verify -checksum-out out.txt.sha256
+cmp out.txt testdata/out_offset0_limit0.txt
+sha256sum -c out.txt.sha256
+
+./go-cp -r -from testdata -to out
+diff -r testdata out
+
+rm -rf go-cp out.txt out.txt.sha256 out
 echo "PASS"
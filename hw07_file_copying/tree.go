@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vbauerster/mpb"
+)
+
+var (
+	ErrRangeForDir  = errors.New("offset and limit are unsupported for directories")
+	ErrSymlinkCycle = errors.New("symlink cycle")
+)
+
+// dirCreateMode is a mode of created directories until their content is copied,
+// so even read-only directories can be filled.
+const dirCreateMode = 0o700
+
+// FileError is an error of copying one entry of a directory tree.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// TreeErrors is a list of errors of copying entries of a directory tree.
+// Entries which are copied without errors stay in destination.
+type TreeErrors []FileError
+
+func (e TreeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// treeEntry is a file, directory or symlink to copy in recursive mode.
+type treeEntry struct {
+	from, to string
+	// rel is a path of the entry relative to the tree root.
+	rel  string
+	info os.FileInfo
+}
+
+// treePlanner collects entries of a directory tree before copying,
+// so the total size to copy is known.
+type treePlanner struct {
+	follow  bool
+	entries []treeEntry
+	errs    TreeErrors
+}
+
+// copyTree copies directory fromPath into toPath recursively, preserving
+// permissions and modification times. Symlinks are recreated or followed
+// according with cfg. Errors of single entries don't stop copying,
+// they are returned together as TreeErrors.
+func copyTree(fromPath, toPath string, offset, limit int64, cfg config) (err error) {
+	if offset != 0 || limit != 0 {
+		return ErrRangeForDir
+	}
+
+	info, err := os.Stat(fromPath)
+	if err != nil {
+		return fmt.Errorf("can't get file info for %s: %w", fromPath, err)
+	}
+
+	planner := &treePlanner{follow: cfg.followSymlinks}
+	planner.walk(treeEntry{from: fromPath, to: toPath, rel: ".", info: info}, nil)
+
+	var size int64
+
+	for _, entry := range planner.entries {
+		if entry.info.Mode().IsRegular() {
+			size += entry.info.Size()
+		}
+	}
+
+	container := newContainer(cfg)
+	total := newProgressBar(container, "total", size)
+
+	if cfg.progress == ProgressJSON {
+		stop := startJSONProgress(cfg.progressOutput(), total, "total", size)
+		defer func() { stop(err) }()
+	}
+
+	errs := planner.errs
+	sums := make([]checksumLine, 0, len(planner.entries))
+
+	for _, entry := range planner.entries {
+		sum, err := copyTreeEntry(container, total, entry, cfg)
+		if err != nil {
+			errs = append(errs, FileError{Path: entry.from, Err: err})
+			continue
+		}
+
+		if sum != nil {
+			sums = append(sums, checksumLine{sum: sum, name: filepath.ToSlash(entry.rel)})
+		}
+	}
+
+	// Directories attributes are set after their content is copied, deepest first,
+	// because copying into directory changes its modification time.
+	for i := len(planner.entries) - 1; i >= 0; i-- {
+		entry := planner.entries[i]
+		if !entry.info.IsDir() {
+			continue
+		}
+
+		if err := setAttributes(entry); err != nil {
+			errs = append(errs, FileError{Path: entry.from, Err: err})
+		}
+	}
+
+	completeProgressBar(total)
+	container.Wait()
+
+	if err := writeChecksumFile(cfg.checksumOut, sums); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// walk adds entry and its descendants into planner. Directories are added
+// before their content. parents are directories which contain entry,
+// they are used to detect cycles when symlinks are followed. Directory
+// closing a cycle is reported and not added.
+func (p *treePlanner) walk(entry treeEntry, parents []os.FileInfo) {
+	if entry.info.IsDir() {
+		for _, parent := range parents {
+			if os.SameFile(parent, entry.info) {
+				p.fail(entry.from, ErrSymlinkCycle)
+				return
+			}
+		}
+	}
+
+	p.entries = append(p.entries, entry)
+
+	if !entry.info.IsDir() {
+		return
+	}
+
+	children, err := ioutil.ReadDir(entry.from)
+	if err != nil {
+		p.fail(entry.from, fmt.Errorf("can't read dir: %w", err))
+		return
+	}
+
+	parents = append(parents, entry.info)
+
+	for _, child := range children {
+		from := filepath.Join(entry.from, child.Name())
+
+		if p.follow && child.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Stat(from)
+			if err != nil {
+				p.fail(from, fmt.Errorf("can't follow symlink: %w", err))
+				continue
+			}
+
+			child = target
+		}
+
+		p.walk(treeEntry{
+			from: from,
+			to:   filepath.Join(entry.to, child.Name()),
+			rel:  filepath.Join(entry.rel, child.Name()),
+			info: child,
+		}, parents)
+	}
+}
+
+func (p *treePlanner) fail(path string, err error) {
+	p.errs = append(p.errs, FileError{Path: path, Err: err})
+}
+
+// copyTreeEntry copies file, creates directory or symlink for entry.
+// It returns digest of copied file if it is required by cfg, else nil.
+func copyTreeEntry(container *mpb.Progress, total *mpb.Bar, entry treeEntry, cfg config) ([]byte, error) {
+	mode := entry.info.Mode()
+
+	switch {
+	case mode.IsDir():
+		if err := os.MkdirAll(entry.to, dirCreateMode); err != nil {
+			return nil, fmt.Errorf("can't create dir %s: %w", entry.to, err)
+		}
+
+		return nil, nil
+	case mode&os.ModeSymlink != 0:
+		return nil, copySymlink(entry)
+	case mode.IsRegular():
+		sum, err := copyFile(container, total, entry.from, entry.to, 0, 0, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return sum, setAttributes(entry)
+	default:
+		return nil, ErrUnsupportedFile
+	}
+}
+
+// copySymlink creates symlink entry.to with the same target as entry.from.
+func copySymlink(entry treeEntry) error {
+	target, err := os.Readlink(entry.from)
+	if err != nil {
+		return fmt.Errorf("can't read symlink: %w", err)
+	}
+
+	if info, err := os.Lstat(entry.to); err == nil && !info.IsDir() {
+		if err := os.Remove(entry.to); err != nil {
+			return fmt.Errorf("can't replace %s: %w", entry.to, err)
+		}
+	}
+
+	if err := os.Symlink(target, entry.to); err != nil {
+		return fmt.Errorf("can't create symlink %s: %w", entry.to, err)
+	}
+
+	return nil
+}
+
+// setAttributes sets permissions and modification time of entry.from to entry.to.
+func setAttributes(entry treeEntry) error {
+	if err := os.Chmod(entry.to, entry.info.Mode().Perm()); err != nil {
+		return fmt.Errorf("can't change mode of %s: %w", entry.to, err)
+	}
+
+	mtime := entry.info.ModTime()
+
+	if err := os.Chtimes(entry.to, mtime, mtime); err != nil {
+		return fmt.Errorf("can't change times of %s: %w", entry.to, err)
+	}
+
+	return nil
+}
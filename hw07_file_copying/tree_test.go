@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTree(t *testing.T) {
+	mtime := time.Date(2020, 11, 22, 10, 0, 0, 0, time.UTC)
+
+	t.Run("PreserveSymlinks", func(t *testing.T) {
+		from := tempTree(t, mtime)
+		to := filepath.Join(tempDir(t), "copy")
+
+		if err := Copy(from, to, 0, 0, WithRecursive()); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, filepath.Join(to, "a.txt"), []byte("aaa"))
+		requireFileContent(t, filepath.Join(to, "sub", "b.txt"), []byte("bbbb"))
+		requireMode(t, filepath.Join(to, "a.txt"), 0o640)
+		requireMode(t, filepath.Join(to, "sub"), 0o750)
+		requireModTime(t, filepath.Join(to, "a.txt"), mtime)
+		requireModTime(t, filepath.Join(to, "sub"), mtime)
+
+		target, err := os.Readlink(filepath.Join(to, "link"))
+		if err != nil {
+			t.Fatalf("unexpected error in Readlink: %v", err)
+		}
+
+		if target != "a.txt" {
+			t.Fatalf("unexpected symlink target: %s, expected: %s", target, "a.txt")
+		}
+	})
+
+	t.Run("FollowSymlinks", func(t *testing.T) {
+		from := tempTree(t, mtime)
+		to := filepath.Join(tempDir(t), "copy")
+
+		if err := Copy(from, to, 0, 0, WithRecursive(), WithFollowSymlinks()); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		info, err := os.Lstat(filepath.Join(to, "link"))
+		if err != nil {
+			t.Fatalf("unexpected error in Lstat: %v", err)
+		}
+
+		if !info.Mode().IsRegular() {
+			t.Fatalf("unexpected mode of followed symlink: %v", info.Mode())
+		}
+
+		requireFileContent(t, filepath.Join(to, "link"), []byte("aaa"))
+	})
+
+	t.Run("FollowSymlinks/Cycle", func(t *testing.T) {
+		from := tempTree(t, mtime)
+		to := filepath.Join(tempDir(t), "copy")
+
+		if err := os.Symlink("..", filepath.Join(from, "sub", "parent")); err != nil {
+			t.Fatalf("unexpected error in Symlink: %v", err)
+		}
+
+		err := Copy(from, to, 0, 0, WithRecursive(), WithFollowSymlinks())
+
+		var treeErrs TreeErrors
+		if !errors.As(err, &treeErrs) {
+			t.Fatalf("unexpected error in Copy: %v, expected: TreeErrors", err)
+		}
+
+		if len(treeErrs) != 1 || !errors.Is(treeErrs[0], ErrSymlinkCycle) {
+			t.Fatalf("unexpected errors in Copy: %v, expected: %v", treeErrs, ErrSymlinkCycle)
+		}
+
+		requireFileContent(t, filepath.Join(to, "sub", "b.txt"), []byte("bbbb"))
+
+		if _, err := os.Lstat(filepath.Join(to, "sub", "parent")); !os.IsNotExist(err) {
+			t.Fatalf("unexpected error in Lstat: %v, expected: not exist", err)
+		}
+	})
+
+	t.Run("Checksums", func(t *testing.T) {
+		from := tempTree(t, mtime)
+		to := filepath.Join(tempDir(t), "copy")
+		sumFile := filepath.Join(tempDir(t), "SHA256SUMS")
+
+		if err := Copy(from, to, 0, 0, WithRecursive(), WithVerify(), WithChecksumOut(sumFile)); err != nil {
+			t.Fatalf("unexpected error in Copy: %v", err)
+		}
+
+		requireFileContent(t, sumFile, []byte(
+			"9834876dcfb05cb167a5c24953eba58c4ac89b1adf57f28f2f9d09af107ee8f0  a.txt\n"+
+				"81cc5b17018674b401b42f35ba07bb79e211239c23bffe658da1577e3e646877  sub/b.txt\n",
+		))
+	})
+
+	t.Run("RangeForDir", func(t *testing.T) {
+		from := tempTree(t, mtime)
+		to := filepath.Join(tempDir(t), "copy")
+
+		err := Copy(from, to, 1, 0, WithRecursive())
+		if err != ErrRangeForDir {
+			t.Fatalf(
+				"unexpected error in Copy: %v, expected: %v",
+				err, ErrRangeForDir,
+			)
+		}
+	})
+}
+
+// tempTree creates directory tree for copying:
+//
+//	a.txt
+//	link -> a.txt
+//	sub/b.txt
+func tempTree(t *testing.T, mtime time.Time) string {
+	t.Helper()
+
+	dir := filepath.Join(tempDir(t), "tree")
+	sub := filepath.Join(dir, "sub")
+
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatalf("unexpected error in MkdirAll: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(dir, "a.txt"): "aaa",
+		filepath.Join(sub, "b.txt"): "bbbb",
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(name, []byte(content), 0o640); err != nil {
+			t.Fatalf("unexpected error in WriteFile: %v", err)
+		}
+
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatalf("unexpected error in Chtimes: %v", err)
+		}
+	}
+
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("unexpected error in Symlink: %v", err)
+	}
+
+	if err := os.Chtimes(sub, mtime, mtime); err != nil {
+		t.Fatalf("unexpected error in Chtimes: %v", err)
+	}
+
+	return dir
+}
+
+// tempDir creates temporary directory which is removed after the test.
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("/tmp", "test-copy-*")
+	if err != nil {
+		t.Fatalf("unexpected error in TempDir: %v", err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func requireMode(t *testing.T, name string, expected os.FileMode) {
+	t.Helper()
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("unexpected error in Stat: %v", err)
+	}
+
+	if info.Mode().Perm() != expected {
+		t.Fatalf("unexpected mode of %s: %v, expected: %v", name, info.Mode().Perm(), expected)
+	}
+}
+
+func requireModTime(t *testing.T, name string, expected time.Time) {
+	t.Helper()
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("unexpected error in Stat: %v", err)
+	}
+
+	if !info.ModTime().Equal(expected) {
+		t.Fatalf("unexpected modification time of %s: %v, expected: %v", name, info.ModTime(), expected)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const defaultHash = "sha256"
+
+var (
+	ErrUnknownHash        = errors.New("unknown hash algorithm")
+	ErrVerificationFailed = errors.New("destination digest doesn't match source digest")
+)
+
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hashByName returns constructor of hash algorithm with name.
+// It returns ErrUnknownHash if algorithm is not supported.
+func hashByName(name string) (func() hash.Hash, error) {
+	newHash, ok := hashes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, supported: %s", ErrUnknownHash, name, strings.Join(hashNames(), ", "))
+	}
+
+	return newHash, nil
+}
+
+// hashNames returns sorted names of supported hash algorithms.
+func hashNames() []string {
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// newDigest returns hash to compute digest of copied data
+// or nil if neither verification nor checksum file is requested.
+func newDigest(cfg config) hash.Hash {
+	if !cfg.verify && cfg.checksumOut == "" {
+		return nil
+	}
+
+	if cfg.newHash == nil {
+		return hashes[defaultHash]()
+	}
+
+	return cfg.newHash()
+}
+
+// verifyDigest returns sum of digest computed for source. If verification
+// is enabled, it compares the sum with digest of toPath and returns
+// ErrVerificationFailed if they differ.
+func verifyDigest(digest hash.Hash, toPath string, cfg config) ([]byte, error) {
+	sum := digest.Sum(nil)
+
+	if !cfg.verify {
+		return sum, nil
+	}
+
+	digest.Reset()
+
+	if err := hashFile(digest, toPath); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(sum, digest.Sum(nil)) {
+		return nil, ErrVerificationFailed
+	}
+
+	return sum, nil
+}
+
+// checksumLine is a digest of file with name in checksum file.
+type checksumLine struct {
+	sum  []byte
+	name string
+}
+
+// writeChecksumFile writes lines into checksum file name in format of
+// sha256sum and similar tools. Nothing is written if name is empty.
+func writeChecksumFile(name string, lines []checksumLine) error {
+	if name == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	for _, line := range lines {
+		fmt.Fprintf(&buf, "%x  %s\n", line.sum, line.name)
+	}
+
+	if err := ioutil.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("can't write checksum file %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// hashFile writes all content of file name into h.
+func hashFile(h hash.Hash, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("can't open file %s: %w", name, err)
+	}
+
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("can't read file %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// hashSection writes size bytes of f starting at offset into h.
+func hashSection(h hash.Hash, f *os.File, offset, size int64) error {
+	if _, err := io.Copy(h, io.NewSectionReader(f, offset, size)); err != nil {
+		return fmt.Errorf("can't read file %s: %w", f.Name(), err)
+	}
+
+	return nil
+}
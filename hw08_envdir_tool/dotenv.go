@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrInvalidLine = errors.New("invalid line")
+
+// exportPrefix is an optional prefix of dotenv lines,
+// so the file can be sourced by shell too.
+const exportPrefix = "export "
+
+// ReadEnvFile reads a dotenv file and returns map of env variables.
+// Each line of the file is a NAME=VALUE pair, empty lines and lines
+// starting with "#" are skipped. VALUE may be enclosed in single quotes,
+// which keep it as is, or in double quotes, which support \n, \t, \", \\
+// escapes. Unquoted values are trimmed and may be followed by " # comment".
+// It returns ErrInvalidLine or ErrInvalidName with position of malformed line.
+func ReadEnvFile(name string) (Environment, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %s: %w", name, err)
+	}
+
+	defer f.Close()
+
+	env := make(Environment)
+	scanner := bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, exportPrefix)
+
+		key, value, err := parseEnvLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+
+		env[key] = EnvValue{Value: value, Source: fmt.Sprintf("%s:%d", name, n)}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read file %s: %w", name, err)
+	}
+
+	return env, nil
+}
+
+// parseEnvLine splits line into variable name and unquoted value.
+func parseEnvLine(line string) (string, string, error) {
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return "", "", fmt.Errorf("%w %q: missing \"=\"", ErrInvalidLine, line)
+	}
+
+	key := strings.TrimSpace(line[:i])
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("%w %q", ErrInvalidName, key)
+	}
+
+	raw := strings.TrimSpace(line[i+1:])
+
+	var (
+		value string
+		rest  string
+		err   error
+	)
+
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		value, rest, err = unquoteDouble(raw[1:])
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			err = errors.New("unterminated quote")
+			break
+		}
+
+		value, rest = raw[1:end+1], raw[end+2:]
+	default:
+		if j := strings.Index(raw, " #"); j >= 0 {
+			raw = raw[:j]
+		}
+
+		return key, strings.TrimRight(raw, " \t"), nil
+	}
+
+	if err != nil {
+		return "", "", fmt.Errorf("%w %q: %v", ErrInvalidLine, line, err)
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", fmt.Errorf("%w %q: unexpected %q after value", ErrInvalidLine, line, rest)
+	}
+
+	return key, value, nil
+}
+
+// unquoteDouble returns content of double quoted string s without
+// the opening quote and the rest of s after the closing quote.
+func unquoteDouble(s string) (string, string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", "", errors.New("unterminated quote")
+			}
+
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", "", errors.New("unterminated quote")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		name := tempEnvFile(t, `# comment
+
+PLAIN=value
+SPACES =  value with spaces  # comment
+export EXPORTED=1
+DOUBLE="line\nnext \"quoted\" \\ \$" # comment
+SINGLE='raw \n # value'
+EMPTY=
+`)
+
+		env, err := ReadEnvFile(name)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadEnvFile: %v", err)
+		}
+
+		expected := Environment{
+			"PLAIN":    {Value: "value", Source: name + ":3"},
+			"SPACES":   {Value: "value with spaces", Source: name + ":4"},
+			"EXPORTED": {Value: "1", Source: name + ":5"},
+			"DOUBLE":   {Value: "line\nnext \"quoted\" \\ \\$", Source: name + ":6"},
+			"SINGLE":   {Value: `raw \n # value`, Source: name + ":7"},
+			"EMPTY":    {Value: "", Source: name + ":8"},
+		}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "MissingEquals", content: "FOO\n", err: ErrInvalidLine},
+		{name: "EmptyName", content: "=foo\n", err: ErrInvalidName},
+		{name: "SpaceInName", content: "FOO BAR=foo\n", err: ErrInvalidName},
+		{name: "UnterminatedDouble", content: `FOO="foo` + "\n", err: ErrInvalidLine},
+		{name: "UnterminatedSingle", content: "FOO='foo\n", err: ErrInvalidLine},
+		{name: "TextAfterQuote", content: `FOO="foo" bar` + "\n", err: ErrInvalidLine},
+	}
+
+	for _, tc := range invalid {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			name := tempEnvFile(t, tc.content)
+
+			_, err := ReadEnvFile(name)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error in ReadEnvFile: %v, expected: %v", err, tc.err)
+			}
+		})
+	}
+}
+
+// tempEnvFile creates dotenv file with content which is removed after the test.
+func tempEnvFile(t *testing.T, content string) string {
+	t.Helper()
+
+	f, err := ioutil.TempFile("/tmp", "test-envdir-*.env")
+	if err != nil {
+		t.Fatalf("unexpected error in TempFile: %v", err)
+	}
+
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("unexpected error in WriteString: %v", err)
+	}
+
+	return f.Name()
+}
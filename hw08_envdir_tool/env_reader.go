@@ -1,10 +1,95 @@
 package main
 
-type Environment map[string]string
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidName = errors.New("invalid variable name")
+
+type Environment map[string]EnvValue
+
+// EnvValue helps to distinguish between empty values and values that must be removed.
+// Source is a file the value is read from, it is shown in print mode.
+type EnvValue struct {
+	Value      string
+	NeedRemove bool
+	Source     string
+}
 
 // ReadDir reads a specified directory and returns map of env variables.
 // Variables represented as files where filename is name of variable, file first line is a value.
+// Trailing spaces and tabs of the value are removed, zero bytes are replaced with new lines.
+// Empty file means that variable must be removed. It returns ErrInvalidName
+// if filename contains "=".
 func ReadDir(dir string) (Environment, error) {
-	// Place your code here
-	return nil, nil
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read dir %s: %w", dir, err)
+	}
+
+	env := make(Environment, len(files))
+
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+
+		// Symlinks are checked by their targets, like links to directories
+		// and files of Kubernetes ConfigMap volumes.
+		if file.Mode()&os.ModeSymlink != 0 {
+			if file, err = os.Stat(path); err != nil {
+				return nil, fmt.Errorf("can't get file info for %s: %w", path, err)
+			}
+		}
+
+		if file.IsDir() {
+			continue
+		}
+
+		if strings.Contains(file.Name(), "=") {
+			return nil, fmt.Errorf("%w %q", ErrInvalidName, file.Name())
+		}
+
+		if file.Size() == 0 {
+			env[file.Name()] = EnvValue{NeedRemove: true, Source: path}
+			continue
+		}
+
+		value, err := readValue(path)
+		if err != nil {
+			return nil, err
+		}
+
+		env[file.Name()] = EnvValue{Value: value, Source: path}
+	}
+
+	return env, nil
+}
+
+// readValue returns the first line of file name without trailing spaces
+// and tabs and with zero bytes replaced by new lines.
+func readValue(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("can't open file %s: %w", name, err)
+	}
+
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("can't read file %s: %w", name, err)
+	}
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimRight(line, " \t")
+	line = bytes.ReplaceAll(line, []byte("\x00"), []byte("\n"))
+
+	return string(line), nil
 }
@@ -1,7 +1,158 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
 
 func TestReadDir(t *testing.T) {
-	// Place your code here
+	t.Run("TestData", func(t *testing.T) {
+		env, err := ReadDir("./testdata/env")
+		if err != nil {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+
+		expected := Environment{
+			"BAR":   {Value: "bar", Source: "testdata/env/BAR"},
+			"FOO":   {Value: "   foo\nwith new line", Source: "testdata/env/FOO"},
+			"HELLO": {Value: `"hello"`, Source: "testdata/env/HELLO"},
+			"UNSET": {NeedRemove: true, Source: "testdata/env/UNSET"},
+		}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	t.Run("TrailingSpaces", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{
+			"SPACES": "value \t \nsecond line",
+			"EMPTY":  "\n",
+		})
+
+		env, err := ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+
+		expected := Environment{
+			"SPACES": {Value: "value", Source: filepath.Join(dir, "SPACES")},
+			"EMPTY":  {Value: "", Source: filepath.Join(dir, "EMPTY")},
+		}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	t.Run("SkipDirs", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{"FOO": "foo"})
+
+		if err := os.Mkdir(filepath.Join(dir, "BAR"), 0o755); err != nil {
+			t.Fatalf("unexpected error in Mkdir: %v", err)
+		}
+
+		env, err := ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+
+		expected := Environment{"FOO": {Value: "foo", Source: filepath.Join(dir, "FOO")}}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	t.Run("Symlinks", func(t *testing.T) {
+		// Layout of Kubernetes ConfigMap volume.
+		dir := tempEnvDir(t, nil)
+		data := filepath.Join(dir, "..2024_01_01")
+
+		if err := os.Mkdir(data, 0o755); err != nil {
+			t.Fatalf("unexpected error in Mkdir: %v", err)
+		}
+
+		files := map[string]string{"FOO": "foo", "UNSET": ""}
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(data, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("unexpected error in WriteFile: %v", err)
+			}
+		}
+
+		links := map[string]string{"..data": "..2024_01_01", "FOO": "..data/FOO", "UNSET": "..data/UNSET"}
+		for name, target := range links {
+			if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+				t.Fatalf("unexpected error in Symlink: %v", err)
+			}
+		}
+
+		env, err := ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+
+		expected := Environment{
+			"FOO":   {Value: "foo", Source: filepath.Join(dir, "FOO")},
+			"UNSET": {NeedRemove: true, Source: filepath.Join(dir, "UNSET")},
+		}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	t.Run("Symlinks/Dangling", func(t *testing.T) {
+		dir := tempEnvDir(t, nil)
+
+		if err := os.Symlink("missing", filepath.Join(dir, "FOO")); err != nil {
+			t.Fatalf("unexpected error in Symlink: %v", err)
+		}
+
+		if _, err := ReadDir(dir); !os.IsNotExist(errors.Unwrap(err)) {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{"FOO=BAR": "foo"})
+
+		_, err := ReadDir(dir)
+		if !errors.Is(err, ErrInvalidName) {
+			t.Fatalf(
+				"unexpected error in ReadDir: %v, expected: %v",
+				err, ErrInvalidName,
+			)
+		}
+	})
+
+	t.Run("MissingDir", func(t *testing.T) {
+		if _, err := ReadDir("./testdata/missing"); err == nil {
+			t.Fatal("expected error in ReadDir")
+		}
+	})
+}
+
+// tempEnvDir creates directory with files which is removed after the test.
+func tempEnvDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("/tmp", "test-envdir-*")
+	if err != nil {
+		t.Fatalf("unexpected error in TempDir: %v", err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error in WriteFile: %v", err)
+		}
+	}
+
+	return dir
 }
@@ -1,7 +1,159 @@
 package main
 
-// RunCmd runs a command + arguments (cmd) with environment variables from env
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// failedExitCode is returned by RunCmd if command can't be run.
+const failedExitCode = 111
+
+// signalExitCodeBase is added to a signal number to get the exit code
+// of a command killed by the signal, as shells do.
+const signalExitCodeBase = 128
+
+// RunCmd runs a command + arguments (cmd) with environment variables from env.
+// Standard streams are passed to the command, its exit code is returned.
+// Signals received while the command is running are forwarded to it.
+// If the command is killed by a signal, 128 + signal number is returned.
 func RunCmd(cmd []string, env Environment) (returnCode int) {
-	// Place your code here
-	return
+	// Signals are caught before the command is started,
+	// so none of them kills go-envdir instead of the command.
+	forwarder := newSignalForwarder()
+	defer forwarder.stop()
+
+	command, group, err := startCmd(cmd, env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedExitCode
+	}
+
+	forwarder.start(command.Process.Pid, group)
+
+	return exitCode(command.Wait())
+}
+
+// startCmd starts a command + arguments (cmd) with environment variables
+// from env and standard streams of go-envdir. It reports whether
+// the command is started in its own process group.
+func startCmd(cmd []string, env Environment) (*exec.Cmd, bool, error) {
+	if len(cmd) == 0 {
+		return nil, false, errors.New("command is missing")
+	}
+
+	command := exec.Command(cmd[0], cmd[1:]...) //nolint:gosec
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = mergeEnv(os.Environ(), env)
+
+	// The command gets its own process group, so forwarded signals reach
+	// its children too. Commands run in the foreground of a terminal stay
+	// in its foreground group to be able to use it and receive job-control
+	// signals, even if their stdin is redirected.
+	group := !inForegroundGroup()
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: group}
+
+	if err := command.Start(); err != nil {
+		return nil, false, err
+	}
+
+	return command, group, nil
+}
+
+// ExecCmd replaces the current process with a command + arguments (cmd)
+// with environment variables from env. Signals are delivered to
+// the command directly, because it has the same pid. It returns
+// only if the command can't be executed.
+func ExecCmd(cmd []string, env Environment) error {
+	if len(cmd) == 0 {
+		return errors.New("command is missing")
+	}
+
+	path, err := exec.LookPath(cmd[0])
+	if err != nil {
+		return err
+	}
+
+	if err := syscall.Exec(path, cmd, mergeEnv(os.Environ(), env)); err != nil { //nolint:gosec
+		return fmt.Errorf("can't exec %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// exitCode returns exit code of a command finished with err.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return failedExitCode
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalExitCodeBase + int(status.Signal())
+	}
+
+	return exitErr.ExitCode()
+}
+
+// inForegroundGroup reports whether go-envdir belongs to the foreground
+// process group of its controlling terminal. Processes without
+// a controlling terminal aren't in the foreground.
+func inForegroundGroup() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+
+	defer tty.Close()
+
+	var pgrp int32
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, tty.Fd(), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgrp)), //nolint:gosec
+	)
+	if errno != 0 {
+		return false
+	}
+
+	return int(pgrp) == syscall.Getpgrp()
+}
+
+// mergeEnv returns environ in "key=value" form with variables
+// from env set or removed.
+func mergeEnv(environ []string, env Environment) []string {
+	merged := make([]string, 0, len(environ)+len(env))
+
+	for _, kv := range environ {
+		name := kv
+		if i := strings.Index(kv, "="); i >= 0 {
+			name = kv[:i]
+		}
+
+		if _, ok := env[name]; ok {
+			continue
+		}
+
+		merged = append(merged, kv)
+	}
+
+	for name, value := range env {
+		if value.NeedRemove {
+			continue
+		}
+
+		merged = append(merged, name+"="+value.Value)
+	}
+
+	return merged
 }
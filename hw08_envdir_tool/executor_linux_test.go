@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestInForegroundGroup(t *testing.T) {
+	t.Run("RedirectedStdin", func(t *testing.T) {
+		pts := openPts(t)
+
+		// The helper is a session leader with pts as its controlling
+		// terminal, so its process group is in the foreground. Its stdin
+		// is /dev/null as in "cat file | go-envdir dir less".
+		command := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		command.Env = append(os.Environ(), helperEnv+"=foreground")
+		command.Stdout = pts
+		command.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 1}
+
+		if err := command.Run(); err != nil {
+			t.Fatalf("helper isn't in the foreground group: %v", err)
+		}
+	})
+
+	t.Run("NoTerminal", func(t *testing.T) {
+		command := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		command.Env = append(os.Environ(), helperEnv+"=foreground")
+		command.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+		if code := exitCode(command.Run()); code != 1 {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, 1)
+		}
+	})
+}
+
+// openPts opens a new pseudo-terminal and returns its slave side.
+func openPts(t *testing.T) *os.File {
+	t.Helper()
+
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals are unavailable: %v", err)
+	}
+
+	t.Cleanup(func() { ptmx.Close() })
+
+	var unlock int32
+	if err := ioctl(ptmx, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		t.Fatalf("unexpected error in unlockpt: %v", err)
+	}
+
+	var n uint32
+	if err := ioctl(ptmx, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		t.Fatalf("unexpected error in ptsname: %v", err)
+	}
+
+	pts, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatalf("unexpected error in OpenFile: %v", err)
+	}
+
+	t.Cleanup(func() { pts.Close() })
+
+	return pts
+}
+
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
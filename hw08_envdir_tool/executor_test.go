@@ -1,7 +1,72 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
 
 func TestRunCmd(t *testing.T) {
-	// Place your code here
+	t.Run("ExitCode", func(t *testing.T) {
+		code := RunCmd([]string{"/bin/sh", "-c", "exit 3"}, Environment{})
+		if code != 3 {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, 3)
+		}
+	})
+
+	t.Run("Environment", func(t *testing.T) {
+		os.Setenv("ENVDIR_TEST_REPLACE", "old")
+		os.Setenv("ENVDIR_TEST_REMOVE", "old")
+
+		t.Cleanup(func() {
+			os.Unsetenv("ENVDIR_TEST_REPLACE")
+			os.Unsetenv("ENVDIR_TEST_REMOVE")
+		})
+
+		env := Environment{
+			"ENVDIR_TEST_REPLACE": {Value: "new"},
+			"ENVDIR_TEST_REMOVE":  {NeedRemove: true},
+			"ENVDIR_TEST_ADD":     {Value: "added"},
+		}
+
+		script := `[ "$ENVDIR_TEST_REPLACE" = new ] && [ -z "${ENVDIR_TEST_REMOVE+set}" ] && [ "$ENVDIR_TEST_ADD" = added ]`
+
+		code := RunCmd([]string{"/bin/sh", "-c", script}, env)
+		if code != 0 {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, 0)
+		}
+	})
+
+	t.Run("MissingCommand", func(t *testing.T) {
+		code := RunCmd([]string{"./testdata/missing"}, Environment{})
+		if code != failedExitCode {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, failedExitCode)
+		}
+	})
+
+	t.Run("EmptyCommand", func(t *testing.T) {
+		code := RunCmd(nil, Environment{})
+		if code != failedExitCode {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, failedExitCode)
+		}
+	})
+}
+
+func TestMergeEnv(t *testing.T) {
+	environ := []string{"KEEP=1", "REPLACE=2", "REMOVE=3", "EMPTY="}
+	env := Environment{
+		"REPLACE": {Value: "new"},
+		"REMOVE":  {NeedRemove: true},
+		"ADD":     {Value: "4"},
+	}
+
+	merged := mergeEnv(environ, env)
+	sort.Strings(merged)
+
+	expected := []string{"ADD=4", "EMPTY=", "KEEP=1", "REPLACE=new"}
+
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("unexpected environment: %v, expected: %v", merged, expected)
+	}
 }
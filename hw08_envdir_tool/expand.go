@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrExpansionCycle   = errors.New("expansion cycle")
+	ErrInvalidReference = errors.New("invalid reference")
+)
+
+// defaultSeparator separates variable name and default value in a reference.
+const defaultSeparator = ":-"
+
+// Expand returns env with ${VAR} and ${VAR:-default} references in values
+// replaced by values of referenced variables. Variables are looked up
+// in env first and then by lookup, usually os.LookupEnv. A reference
+// of a variable to itself is looked up by lookup only, so values like
+// ${PATH}:/bin extend the parent environment. Default value is used if
+// the variable is unset or empty, "$$" is replaced by "$". It returns
+// ErrExpansionCycle if variables reference each other and
+// ErrInvalidReference for malformed references, both naming value sources.
+func Expand(env Environment, lookup func(string) (string, bool)) (Environment, error) {
+	e := &expander{
+		env:      env,
+		lookup:   lookup,
+		expanded: make(Environment, len(env)),
+	}
+
+	for name := range env {
+		if _, _, err := e.expandVar(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.expanded, nil
+}
+
+// expander expands values of env recursively.
+type expander struct {
+	env    Environment
+	lookup func(string) (string, bool)
+	// expanded contains variables whose values are already expanded.
+	expanded Environment
+	// stack contains names of variables being expanded.
+	stack []string
+}
+
+// expandVar returns expanded value of variable name
+// and reports whether the variable is set.
+func (e *expander) expandVar(name string) (string, bool, error) {
+	if value, ok := e.expanded[name]; ok {
+		return value.Value, !value.NeedRemove, nil
+	}
+
+	for i, parent := range e.stack {
+		if parent == name {
+			return "", false, e.cycleError(e.stack[i:])
+		}
+	}
+
+	value, ok := e.env[name]
+	if !ok {
+		v, ok := e.lookup(name)
+		return v, ok, nil
+	}
+
+	if !value.NeedRemove {
+		e.stack = append(e.stack, name)
+
+		expanded, err := e.expandString(value.Value, name)
+		if err != nil {
+			return "", false, err
+		}
+
+		e.stack = e.stack[:len(e.stack)-1]
+		value.Value = expanded
+	}
+
+	e.expanded[name] = value
+
+	return value.Value, !value.NeedRemove, nil
+}
+
+// expandString replaces references in s which is a value of variable owner.
+func (e *expander) expandString(s, owner string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", e.referenceError(owner, s[i:], "missing \"}\"")
+			}
+
+			value, err := e.expandReference(s[i+2:end], owner)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(value)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandReference returns value of reference ref in form of "VAR"
+// or "VAR:-default" found in value of variable owner.
+func (e *expander) expandReference(ref, owner string) (string, error) {
+	name, def, hasDefault := ref, "", false
+	if i := strings.Index(ref, defaultSeparator); i >= 0 {
+		name, def, hasDefault = ref[:i], ref[i+len(defaultSeparator):], true
+	}
+
+	if !isValidName(name) {
+		return "", e.referenceError(owner, "${"+ref+"}", "invalid variable name")
+	}
+
+	var (
+		value string
+		ok    bool
+		err   error
+	)
+
+	if name == owner {
+		value, ok = e.lookup(name)
+	} else if value, ok, err = e.expandVar(name); err != nil {
+		return "", err
+	}
+
+	if hasDefault && (!ok || value == "") {
+		return e.expandString(def, owner)
+	}
+
+	return value, nil
+}
+
+// cycleError returns ErrExpansionCycle for variables names
+// each of which references the next one and the last references the first.
+func (e *expander) cycleError(names []string) error {
+	chain := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		chain = append(chain, fmt.Sprintf("%s (%s)", name, e.env[name].Source))
+	}
+
+	chain = append(chain, names[0])
+
+	return fmt.Errorf("%w: %s", ErrExpansionCycle, strings.Join(chain, " -> "))
+}
+
+// referenceError returns ErrInvalidReference for ref in value of variable owner.
+func (e *expander) referenceError(owner, ref, reason string) error {
+	return fmt.Errorf("%s: %w %q in %s: %s", e.env[owner].Source, ErrInvalidReference, ref, owner, reason)
+}
+
+// closingBrace returns index of "}" closing reference started before
+// start in s, taking nested references into account, or -1.
+func closingBrace(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// isValidName reports whether name consists of letters, digits and underscores
+// and doesn't start with a digit.
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	parent := map[string]string{
+		"HOME":  "/home/user",
+		"PATH":  "/usr/bin",
+		"EMPTY": "",
+	}
+
+	lookup := func(name string) (string, bool) {
+		value, ok := parent[name]
+		return value, ok
+	}
+
+	t.Run("Values", func(t *testing.T) {
+		env := Environment{
+			"HOST":     {Value: "db.local", Source: "env/HOST"},
+			"URL":      {Value: "postgres://${HOST}:${PORT:-5432}/${NAME}", Source: "env/URL"},
+			"NAME":     {Value: "${USER:-app}", Source: "env/NAME"},
+			"DATA":     {Value: "${HOME}/data", Source: "env/DATA"},
+			"PATH":     {Value: "${PATH}:${DATA}/bin", Source: "env/PATH"},
+			"FALLBACK": {Value: "${EMPTY:-${UNSET:-${HOST}}}", Source: "env/FALLBACK"},
+			"UNSET":    {NeedRemove: true, Source: "env/UNSET"},
+			"LITERAL":  {Value: "$$HOME $HOME $", Source: "env/LITERAL"},
+			"MISSING":  {Value: "[${MISSING_VAR}]", Source: "env/MISSING"},
+		}
+
+		expanded, err := Expand(env, lookup)
+		if err != nil {
+			t.Fatalf("unexpected error in Expand: %v", err)
+		}
+
+		expected := Environment{
+			"HOST":     {Value: "db.local", Source: "env/HOST"},
+			"URL":      {Value: "postgres://db.local:5432/app", Source: "env/URL"},
+			"NAME":     {Value: "app", Source: "env/NAME"},
+			"DATA":     {Value: "/home/user/data", Source: "env/DATA"},
+			"PATH":     {Value: "/usr/bin:/home/user/data/bin", Source: "env/PATH"},
+			"FALLBACK": {Value: "db.local", Source: "env/FALLBACK"},
+			"UNSET":    {NeedRemove: true, Source: "env/UNSET"},
+			"LITERAL":  {Value: "$HOME $HOME $", Source: "env/LITERAL"},
+			"MISSING":  {Value: "[]", Source: "env/MISSING"},
+		}
+
+		if !reflect.DeepEqual(expanded, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", expanded, expected)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		env := Environment{
+			"A": {Value: "${B}", Source: "env/A"},
+			"B": {Value: "x${C:-y}", Source: "env/B"},
+			"C": {Value: "${A}", Source: "prod.env:2"},
+		}
+
+		_, err := Expand(env, lookup)
+		if !errors.Is(err, ErrExpansionCycle) {
+			t.Fatalf("unexpected error in Expand: %v, expected: %v", err, ErrExpansionCycle)
+		}
+
+		for _, source := range []string{"env/A", "env/B", "prod.env:2"} {
+			if !strings.Contains(err.Error(), source) {
+				t.Fatalf("error %q doesn't name source %s", err, source)
+			}
+		}
+	})
+
+	invalid := []struct {
+		name  string
+		value string
+	}{
+		{name: "Unterminated", value: "${HOST"},
+		{name: "EmptyName", value: "${}"},
+		{name: "InvalidName", value: "${1HOST}"},
+	}
+
+	for _, tc := range invalid {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			env := Environment{"BAD": {Value: tc.value, Source: "env/BAD"}}
+
+			_, err := Expand(env, lookup)
+			if !errors.Is(err, ErrInvalidReference) {
+				t.Fatalf("unexpected error in Expand: %v, expected: %v", err, ErrInvalidReference)
+			}
+
+			if !strings.Contains(err.Error(), "env/BAD") {
+				t.Fatalf("error %q doesn't name source env/BAD", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrUnexportable = errors.New("variable can't be exported")
+
+// environSource is a source of variables of the current environment.
+const environSource = "environment"
+
+const (
+	exportDirMode  = 0o700
+	exportFileMode = 0o600
+)
+
+// ChangeKind is a kind of change of an env directory file.
+type ChangeKind byte
+
+const (
+	ChangeAdd    ChangeKind = '+'
+	ChangeUpdate ChangeKind = '~'
+	ChangeRemove ChangeKind = '-'
+)
+
+// EnvChange is a change of a variable of an env directory made by export.
+type EnvChange struct {
+	Kind ChangeKind
+	Name string
+	Old  EnvValue
+	New  EnvValue
+}
+
+// ParseEnviron returns variables of environ in "key=value" form.
+func ParseEnviron(environ []string) Environment {
+	env := make(Environment, len(environ))
+
+	for _, kv := range environ {
+		if i := strings.Index(kv, "="); i > 0 {
+			env[kv[:i]] = EnvValue{Value: kv[i+1:], Source: environSource}
+		}
+	}
+
+	return env
+}
+
+// ExportableEnv returns variables of env which can be written into
+// an env directory and read back by ReadDir without changes, and
+// ErrUnexportable errors for others: names which can't be file names
+// and values with trailing spaces or tabs, which ReadDir removes.
+func ExportableEnv(env Environment) (Environment, []error) {
+	exportable := make(Environment, len(env))
+
+	var errs []error
+
+	for name, value := range env {
+		if reason := unexportableReason(name, value); reason != "" {
+			errs = append(errs, fmt.Errorf("%w %q from %s: %s", ErrUnexportable, name, value.Source, reason))
+			continue
+		}
+
+		exportable[name] = value
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return exportable, errs
+}
+
+func unexportableReason(name string, value EnvValue) string {
+	switch {
+	case name == "" || name == "." || name == "..":
+		return "invalid file name"
+	case strings.ContainsAny(name, "=/\x00"):
+		return "name contains \"=\", \"/\" or zero byte"
+	case strings.Contains(value.Value, "\x00"):
+		return "value contains zero byte"
+	case strings.TrimRight(value.Value, " \t") != value.Value:
+		return "value has trailing spaces"
+	}
+
+	return ""
+}
+
+// DiffDir returns changes which make env directory dir contain variables
+// of env. Files of variables missing in env are removed only if prune
+// is true. Missing dir is considered empty.
+func DiffDir(dir string, env Environment, prune bool) ([]EnvChange, error) {
+	existing, err := ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		existing, err = Environment{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []EnvChange
+
+	for name, value := range env {
+		old, ok := existing[name]
+
+		switch {
+		case !ok:
+			changes = append(changes, EnvChange{Kind: ChangeAdd, Name: name, New: value})
+		case old.Value != value.Value || old.NeedRemove != value.NeedRemove:
+			changes = append(changes, EnvChange{Kind: ChangeUpdate, Name: name, Old: old, New: value})
+		}
+	}
+
+	if prune {
+		for name, old := range existing {
+			if _, ok := env[name]; !ok {
+				changes = append(changes, EnvChange{Kind: ChangeRemove, Name: name, Old: old})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+
+	return changes, nil
+}
+
+// WriteDir applies changes to env directory dir, creating it if needed.
+// Values are encoded inversely to ReadDir: new lines are replaced with
+// zero bytes, removed variables are written as empty files and empty
+// values as a single new line.
+func WriteDir(dir string, changes []EnvChange) error {
+	if err := os.MkdirAll(dir, exportDirMode); err != nil {
+		return fmt.Errorf("can't create dir %s: %w", dir, err)
+	}
+
+	for _, change := range changes {
+		name := filepath.Join(dir, change.Name)
+
+		if change.Kind == ChangeRemove {
+			if err := os.Remove(name); err != nil {
+				return fmt.Errorf("can't remove file %s: %w", name, err)
+			}
+
+			continue
+		}
+
+		if err := replaceFile(name, encodeValue(change.New)); err != nil {
+			return fmt.Errorf("can't write file %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// replaceFile writes data into a temporary file next to name and renames
+// it over name, so a symlink at name is replaced instead of its target.
+func replaceFile(name string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(exportFileMode)
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// encodeValue returns content of env directory file for value.
+func encodeValue(value EnvValue) []byte {
+	if value.NeedRemove {
+		return nil
+	}
+
+	data := bytes.ReplaceAll([]byte(value.Value), []byte("\n"), []byte("\x00"))
+
+	return append(data, '\n')
+}
+
+// PrintChanges writes changes to w, one per line, with "+" for added,
+// "~" for updated and "-" for removed variables.
+func PrintChanges(w io.Writer, changes []EnvChange) error {
+	for _, change := range changes {
+		var err error
+
+		switch change.Kind {
+		case ChangeAdd:
+			_, err = fmt.Fprintf(w, "+ %s=%s\n", change.Name, formatValue(change.New))
+		case ChangeUpdate:
+			_, err = fmt.Fprintf(w, "~ %s=%s -> %s\n", change.Name, formatValue(change.Old), formatValue(change.New))
+		case ChangeRemove:
+			_, err = fmt.Fprintf(w, "- %s=%s\n", change.Name, formatValue(change.Old))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatValue returns quoted value or "(unset)" for removed variable.
+func formatValue(value EnvValue) string {
+	if value.NeedRemove {
+		return "(unset)"
+	}
+
+	return strconv.Quote(value.Value)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		dir := filepath.Join(tempEnvDir(t, nil), "env")
+		env := Environment{
+			"MULTILINE": {Value: "first\nsecond"},
+			"EMPTY":     {Value: ""},
+			"SPACES":    {Value: "  leading"},
+			"UNSET":     {NeedRemove: true},
+		}
+
+		changes, err := DiffDir(dir, env, false)
+		if err != nil {
+			t.Fatalf("unexpected error in DiffDir: %v", err)
+		}
+
+		if err := WriteDir(dir, changes); err != nil {
+			t.Fatalf("unexpected error in WriteDir: %v", err)
+		}
+
+		read, err := ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadDir: %v", err)
+		}
+
+		for name, value := range read {
+			value.Source = ""
+			read[name] = value
+		}
+
+		if !reflect.DeepEqual(read, env) {
+			t.Fatalf("unexpected environment: %v, expected: %v", read, env)
+		}
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{
+			"SAME":    "same",
+			"CHANGED": "old",
+			"EXTRA":   "extra",
+		})
+		env := Environment{
+			"SAME":    {Value: "same"},
+			"CHANGED": {Value: "new"},
+			"ADDED":   {Value: "added"},
+		}
+
+		changes, err := DiffDir(dir, env, true)
+		if err != nil {
+			t.Fatalf("unexpected error in DiffDir: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := PrintChanges(&out, changes); err != nil {
+			t.Fatalf("unexpected error in PrintChanges: %v", err)
+		}
+
+		expected := "+ ADDED=\"added\"\n" +
+			"~ CHANGED=\"old\" -> \"new\"\n" +
+			"- EXTRA=\"extra\"\n"
+
+		if out.String() != expected {
+			t.Fatalf("unexpected changes: %q, expected: %q", out.String(), expected)
+		}
+
+		if err := WriteDir(dir, changes); err != nil {
+			t.Fatalf("unexpected error in WriteDir: %v", err)
+		}
+
+		if changes, err = DiffDir(dir, env, true); err != nil || len(changes) != 0 {
+			t.Fatalf("unexpected changes after WriteDir: %v, error: %v", changes, err)
+		}
+	})
+
+	t.Run("Symlink", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{"target": "secret"})
+		target := filepath.Join(dir, "target")
+		envDir := filepath.Join(dir, "env")
+
+		if err := os.Mkdir(envDir, 0o755); err != nil {
+			t.Fatalf("unexpected error in Mkdir: %v", err)
+		}
+
+		if err := os.Symlink(target, filepath.Join(envDir, "NAME")); err != nil {
+			t.Fatalf("unexpected error in Symlink: %v", err)
+		}
+
+		if err := WriteDir(envDir, []EnvChange{{Kind: ChangeUpdate, Name: "NAME", New: EnvValue{Value: "value"}}}); err != nil {
+			t.Fatalf("unexpected error in WriteDir: %v", err)
+		}
+
+		requireFile(t, target, "secret")
+		requireFile(t, filepath.Join(envDir, "NAME"), "value\n")
+
+		if info, err := os.Lstat(filepath.Join(envDir, "NAME")); err != nil || !info.Mode().IsRegular() {
+			t.Fatalf("env file isn't replaced by regular file: %v, error: %v", info, err)
+		}
+	})
+
+	t.Run("WithoutPrune", func(t *testing.T) {
+		dir := tempEnvDir(t, map[string]string{"EXTRA": "extra"})
+
+		changes, err := DiffDir(dir, Environment{}, false)
+		if err != nil {
+			t.Fatalf("unexpected error in DiffDir: %v", err)
+		}
+
+		if len(changes) != 0 {
+			t.Fatalf("unexpected changes: %v", changes)
+		}
+	})
+}
+
+func TestExportableEnv(t *testing.T) {
+	env := ParseEnviron([]string{
+		"GOOD=value",
+		"TRAILING=value ",
+		"=C:=C:\\",
+		"A/B=1",
+		"..=1",
+	})
+
+	exportable, errs := ExportableEnv(env)
+
+	expected := Environment{"GOOD": {Value: "value", Source: environSource}}
+	if !reflect.DeepEqual(exportable, expected) {
+		t.Fatalf("unexpected environment: %v, expected: %v", exportable, expected)
+	}
+
+	if len(errs) != 3 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, ErrUnexportable) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrUnexportable)
+		}
+	}
+}
+
+func requireFile(t *testing.T, name, expected string) {
+	t.Helper()
+
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error in ReadFile: %v", err)
+	}
+
+	if string(content) != expected {
+		t.Fatalf("unexpected content of %s: %q, expected: %q", name, content, expected)
+	}
+}
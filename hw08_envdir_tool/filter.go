@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// EnvFilter describes which variables of the parent environment
+// are passed to the command. Variables read from env sources
+// are passed regardless of the filter.
+type EnvFilter struct {
+	// Clean removes all variables of the parent environment except Keep.
+	Clean bool
+	// Keep is a list of names of variables passed in clean mode.
+	// Non-empty Keep enables clean mode.
+	Keep []string
+	// Unset is a list of shell patterns of names of variables to remove.
+	Unset []string
+}
+
+// Apply returns a copy of env with variables of environ ("key=value" form)
+// which don't pass the filter marked as removed.
+func (f EnvFilter) Apply(env Environment, environ []string) (Environment, error) {
+	for _, pattern := range f.Unset {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid unset pattern %q: %w", pattern, err)
+		}
+	}
+
+	filtered := make(Environment, len(env))
+	filtered.Merge(env)
+
+	clean := f.Clean || len(f.Keep) > 0
+
+	for _, kv := range environ {
+		name := kv
+		if i := strings.Index(kv, "="); i >= 0 {
+			name = kv[:i]
+		}
+
+		if _, ok := env[name]; ok {
+			continue
+		}
+
+		if clean && !contains(f.Keep, name) {
+			filtered[name] = EnvValue{NeedRemove: true, Source: "-i"}
+			continue
+		}
+
+		for _, pattern := range f.Unset {
+			if ok, _ := path.Match(pattern, name); ok {
+				filtered[name] = EnvValue{NeedRemove: true, Source: "-unset " + pattern}
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitList splits comma-separated list s skipping empty items.
+func splitList(s string) []string {
+	var items []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvFilterApply(t *testing.T) {
+	environ := []string{"HOME=/home/user", "PATH=/bin", "AWS_KEY=secret", "AWS_REGION=eu", "FOO=parent"}
+	env := Environment{
+		"FOO":        {Value: "env", Source: "env/FOO"},
+		"AWS_REGION": {Value: "us", Source: "env/AWS_REGION"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   EnvFilter
+		expected Environment
+	}{
+		{
+			name:     "Empty",
+			filter:   EnvFilter{},
+			expected: env,
+		},
+		{
+			name:   "Clean",
+			filter: EnvFilter{Clean: true},
+			expected: Environment{
+				"FOO":        {Value: "env", Source: "env/FOO"},
+				"AWS_REGION": {Value: "us", Source: "env/AWS_REGION"},
+				"HOME":       {NeedRemove: true, Source: "-i"},
+				"PATH":       {NeedRemove: true, Source: "-i"},
+				"AWS_KEY":    {NeedRemove: true, Source: "-i"},
+			},
+		},
+		{
+			name:   "Keep",
+			filter: EnvFilter{Keep: []string{"HOME", "PATH"}},
+			expected: Environment{
+				"FOO":        {Value: "env", Source: "env/FOO"},
+				"AWS_REGION": {Value: "us", Source: "env/AWS_REGION"},
+				"AWS_KEY":    {NeedRemove: true, Source: "-i"},
+			},
+		},
+		{
+			name:   "Unset",
+			filter: EnvFilter{Unset: []string{"AWS_*", "F*"}},
+			expected: Environment{
+				"FOO":        {Value: "env", Source: "env/FOO"},
+				"AWS_REGION": {Value: "us", Source: "env/AWS_REGION"},
+				"AWS_KEY":    {NeedRemove: true, Source: "-unset AWS_*"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			filtered, err := tc.filter.Apply(env, environ)
+			if err != nil {
+				t.Fatalf("unexpected error in Apply: %v", err)
+			}
+
+			if !reflect.DeepEqual(filtered, tc.expected) {
+				t.Fatalf("unexpected environment: %v, expected: %v", filtered, tc.expected)
+			}
+		})
+	}
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		if _, err := (EnvFilter{Unset: []string{"AWS_["}}).Apply(env, environ); err == nil {
+			t.Fatal("expected error in Apply")
+		}
+	})
+
+	t.Run("MergeEnv", func(t *testing.T) {
+		filtered, err := EnvFilter{Keep: []string{"PATH"}}.Apply(Environment{}, []string{"HOME=/home/user", "PATH=/bin"})
+		if err != nil {
+			t.Fatalf("unexpected error in Apply: %v", err)
+		}
+
+		merged := mergeEnv([]string{"HOME=/home/user", "PATH=/bin"}, filtered)
+
+		if !reflect.DeepEqual(merged, []string{"PATH=/bin"}) {
+			t.Fatalf("unexpected environment: %v, expected: %v", merged, []string{"PATH=/bin"})
+		}
+	})
+}
+
+func TestSplitList(t *testing.T) {
+	items := splitList(" HOME, ,PATH,")
+	if !reflect.DeepEqual(items, []string{"HOME", "PATH"}) {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
@@ -1,3 +1,3 @@
-module github.com/fixme_my_friend/hw08_envdir_tool
+module github.com/dmirou/otusgopart2/hw08_envdir_tool
 
 go 1.15
@@ -1,5 +1,233 @@
 package main
 
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const helpText = `
+Usage: go-envdir [OPTION]... DIR COMMAND [ARG]...
+       go-envdir -print [OPTION]... DIR
+       go-envdir export [-from SOURCE]... [-dry-run] [-prune] DIR
+Run COMMAND with environment variables read from DIR.
+
+Each file in a directory sets variable named as the file to the first line
+of the file. Empty file removes the variable.
+
+DIR may also be a dotenv file with NAME=VALUE lines. More directories and
+dotenv files are read before DIR with -source options. Variables of later
+sources override variables of earlier ones, DIR overrides all of them.
+
+Signals received by go-envdir are forwarded to COMMAND. If go-envdir runs in
+the foreground of a terminal, INT, QUIT and WINCH are sent to COMMAND by the
+terminal itself and aren't forwarded. If COMMAND is killed by a signal, go-envdir exits with code
+128 + signal number.
+
+The export command writes variables of the current environment or sources
+into directory DIR, so they can be read back by go-envdir. New lines in values
+are written as zero bytes. Variables which can't be read back unchanged,
+like ones with trailing spaces, are skipped with a warning. To use directory
+named "export" as DIR, specify it as "./export".
+
+Options:
+	-source PATH
+		read variables from directory or dotenv file PATH before DIR,
+		may be repeated
+	-exec	replace go-envdir with COMMAND instead of running it as a child
+	-print	print resulting variables and their sources instead of running COMMAND
+	-expand	replace ${VAR} and ${VAR:-default} in values by values of variables
+		from sources or the current environment, "$$" stands for "$"
+	-i	don't pass the current environment to COMMAND
+	-keep VAR,...
+		pass only listed variables of the current environment, implies -i
+	-unset PATTERN,...
+		don't pass variables of the current environment matching shell patterns
+	-watch	restart COMMAND with new environment when sources are changed
+	-restart-signal SIGNAL
+		signal to stop COMMAND before restart (default TERM)
+	-grace DURATION
+		time to wait for COMMAND to stop before killing it (default 10s)
+	-poll-interval DURATION
+		interval of checking sources if inotify is unavailable (default 1s)
+
+Export options:
+	-from SOURCE
+		export variables of directory or dotenv file SOURCE instead
+		of the current environment, may be repeated
+	-dry-run
+		print changes of DIR instead of writing them:
+		"+" for added, "~" for updated and "-" for removed variables
+	-prune	remove variables of DIR missing in the exported environment
+
+Example:
+	go-envdir /path/to/env/dir command arg1 arg2
+	go-envdir -source base -source prod.env secrets command arg1 arg2
+	go-envdir -keep HOME,PATH -unset 'AWS_*' /path/to/env/dir command
+	go-envdir -watch -restart-signal HUP -grace 30s /path/to/env/dir command
+	go-envdir export -from .env -dry-run /path/to/env/dir`
+
+// exportCommand is a name of the subcommand writing variables into a directory.
+const exportCommand = "export"
+
+var ErrWatchMode = errors.New("watch mode can't be used with -exec or -print")
+
+var (
+	sources       sourceList
+	execMode      bool
+	printMode     bool
+	expandMode    bool
+	clean         bool
+	keep          string
+	unset         string
+	watchMode     bool
+	restartSignal string
+	grace         time.Duration
+	pollInterval  time.Duration
+)
+
+func init() {
+	flag.Var(&sources, "source", "directory or dotenv file to read before DIR, may be repeated")
+	flag.BoolVar(&execMode, "exec", false, "replace go-envdir with command")
+	flag.BoolVar(&printMode, "print", false, "print variables and their sources")
+	flag.BoolVar(&expandMode, "expand", false, "expand ${VAR} references in values")
+	flag.BoolVar(&clean, "i", false, "start from an empty environment")
+	flag.StringVar(&keep, "keep", "", "comma-separated variables of the current environment to pass")
+	flag.StringVar(&unset, "unset", "", "comma-separated patterns of variables of the current environment to remove")
+	flag.BoolVar(&watchMode, "watch", false, "restart command when sources are changed")
+	flag.StringVar(&restartSignal, "restart-signal", "TERM", "signal to stop command before restart")
+	flag.DurationVar(&grace, "grace", 10*time.Second, "time to wait for command to stop before killing it")
+	flag.DurationVar(&pollInterval, "poll-interval", time.Second, "interval of checking sources without inotify")
+}
+
 func main() {
-	// Place your code here
+	flag.Usage = func() {
+		fmt.Println(helpText)
+	}
+	if len(os.Args) > 1 && os.Args[1] == exportCommand {
+		os.Exit(runExport(os.Args[2:]))
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 || (flag.NArg() < 2 && !printMode) {
+		flag.Usage()
+		os.Exit(failedExitCode)
+	}
+
+	if watchMode && (execMode || printMode) {
+		fmt.Fprintln(os.Stderr, ErrWatchMode)
+		os.Exit(failedExitCode)
+	}
+
+	sources = append(sources, flag.Arg(0))
+
+	env, err := loadEnv(sources)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(failedExitCode)
+	}
+
+	if printMode {
+		if err := PrintEnv(os.Stdout, env); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(failedExitCode)
+		}
+
+		return
+	}
+
+	cmd := flag.Args()[1:]
+
+	switch {
+	case execMode:
+		err := ExecCmd(cmd, env)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(failedExitCode)
+	case watchMode:
+		sig, err := ParseSignal(restartSignal)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(failedExitCode)
+		}
+
+		opts := WatchOptions{Paths: sources, Signal: sig, Grace: grace, Interval: pollInterval}
+		load := func() (Environment, error) { return loadEnv(sources) }
+
+		os.Exit(WatchCmd(cmd, env, load, opts))
+	default:
+		os.Exit(RunCmd(cmd, env))
+	}
+}
+
+// loadEnv reads sources and applies expansion and filters set by flags.
+func loadEnv(sources []string) (Environment, error) {
+	env, err := ReadSources(sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	if expandMode {
+		if env, err = Expand(env, os.LookupEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := EnvFilter{Clean: clean, Keep: splitList(keep), Unset: splitList(unset)}
+
+	return filter.Apply(env, os.Environ())
+}
+
+// runExport runs the export command with args and returns exit code.
+func runExport(args []string) int {
+	flags := flag.NewFlagSet(exportCommand, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(helpText)
+	}
+
+	var from sourceList
+	flags.Var(&from, "from", "directory or dotenv file to export instead of the current environment, may be repeated")
+	dryRun := flags.Bool("dry-run", false, "print changes instead of writing them")
+	prune := flags.Bool("prune", false, "remove variables missing in the exported environment")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		if err == nil {
+			flags.Usage()
+		}
+
+		return failedExitCode
+	}
+
+	env := ParseEnviron(os.Environ())
+
+	if len(from) > 0 {
+		var err error
+		if env, err = ReadSources(from...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return failedExitCode
+		}
+	}
+
+	env, errs := ExportableEnv(env)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	changes, err := DiffDir(flags.Arg(0), env, *prune)
+	if err == nil {
+		if *dryRun {
+			err = PrintChanges(os.Stdout, changes)
+		} else {
+			err = WriteDir(flags.Arg(0), changes)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedExitCode
+	}
+
+	return 0
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var ErrUnknownSignal = errors.New("unknown signal")
+
+// forwardedSignals are signals which go-envdir passes to the command.
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+	syscall.SIGWINCH,
+}
+
+// terminalSignals are sent by a terminal to its foreground process group.
+var terminalSignals = map[os.Signal]bool{
+	syscall.SIGINT:   true,
+	syscall.SIGQUIT:  true,
+	syscall.SIGWINCH: true,
+}
+
+// signalForwarder passes signals received by go-envdir to a command.
+type signalForwarder struct {
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// newSignalForwarder starts catching forwardedSignals. They are buffered
+// until start is called, so signals received before the command
+// is started are not lost.
+func newSignalForwarder() *signalForwarder {
+	f := &signalForwarder{
+		signals: make(chan os.Signal, len(forwardedSignals)),
+		done:    make(chan struct{}),
+	}
+
+	signal.Notify(f.signals, forwardedSignals...)
+
+	return f
+}
+
+// start forwards caught signals to process pid. If group is true,
+// signals are sent to the whole process group led by pid.
+func (f *signalForwarder) start(pid int, group bool) {
+	go func() {
+		for {
+			select {
+			case sig := <-f.signals:
+				forwardSignal(pid, group, sig)
+			case <-f.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop restores default handling of forwardedSignals.
+func (f *signalForwarder) stop() {
+	signal.Stop(f.signals)
+	close(f.done)
+}
+
+// forwardSignal sends sig caught by go-envdir to process pid. If group is
+// false, the process stays in the foreground group of the terminal, which
+// sends terminalSignals to it directly, so they aren't sent twice.
+func forwardSignal(pid int, group bool, sig os.Signal) {
+	if !group && terminalSignals[sig] {
+		return
+	}
+
+	// The command may be already finished, there is
+	// nothing to do with the error in this case.
+	_ = signalProcess(pid, group, sig.(syscall.Signal))
+}
+
+// signalProcess sends sig to process pid. If group is true,
+// sig is sent to the whole process group led by pid.
+func signalProcess(pid int, group bool, sig syscall.Signal) error {
+	if group {
+		pid = -pid
+	}
+
+	return syscall.Kill(pid, sig)
+}
+
+// signalNames are names of signals accepted by ParseSignal.
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+}
+
+// ParseSignal returns signal by its name with or without "SIG" prefix,
+// like "TERM" or "SIGHUP", or by its number.
+func ParseSignal(s string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+
+	if sig, ok := signalNames[strings.TrimPrefix(strings.ToUpper(s), "SIG")]; ok {
+		return sig, nil
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrUnknownSignal, s)
+}
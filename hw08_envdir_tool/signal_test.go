@@ -0,0 +1,205 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// helperEnv is set for the test binary run as a helper process.
+const helperEnv = "GO_ENVDIR_HELPER_PROCESS"
+
+// TestHelperProcess isn't a real test. It is a command run by RunCmd
+// in signal tests. It creates file named in GO_ENVDIR_HELPER_READY
+// when it is ready to receive signals and then behaves according
+// with its mode. In "foreground" mode it only exits with 0 if it is
+// in the foreground process group of its terminal.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+
+	if mode == "foreground" {
+		if inForegroundGroup() {
+			os.Exit(0)
+		}
+
+		os.Exit(1)
+	}
+
+	signals := make(chan os.Signal, 1)
+	if mode == "trap" {
+		signal.Notify(signals, syscall.SIGTERM)
+	}
+
+	if err := ioutil.WriteFile(os.Getenv("GO_ENVDIR_HELPER_READY"), nil, 0o644); err != nil {
+		os.Exit(1)
+	}
+
+	select {
+	case <-signals:
+		// The exit code tells the test that the signal was received.
+		os.Exit(42)
+	case <-time.After(10 * time.Second):
+		os.Exit(0)
+	}
+}
+
+func TestRunCmdSignals(t *testing.T) {
+	t.Run("Forwarded", func(t *testing.T) {
+		code := runHelper(t, "trap", syscall.SIGTERM)
+		if code != 42 {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, 42)
+		}
+	})
+
+	t.Run("Killed", func(t *testing.T) {
+		code := runHelper(t, "default", syscall.SIGTERM)
+
+		expected := signalExitCodeBase + int(syscall.SIGTERM)
+		if code != expected {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, expected)
+		}
+	})
+
+	t.Run("DevNullStdin", func(t *testing.T) {
+		// Stdin of services and containers without tty is /dev/null,
+		// which is a character device, but not a terminal.
+		if inForegroundGroup() {
+			t.Skip("test is run in the foreground of a terminal")
+		}
+
+		stdin, err := os.Open(os.DevNull)
+		if err != nil {
+			t.Fatalf("unexpected error in Open: %v", err)
+		}
+
+		defer stdin.Close()
+
+		saved := os.Stdin
+		os.Stdin = stdin
+
+		defer func() { os.Stdin = saved }()
+
+		code := runHelper(t, "default", syscall.SIGINT)
+
+		expected := signalExitCodeBase + int(syscall.SIGINT)
+		if code != expected {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, expected)
+		}
+	})
+}
+
+func TestForwardSignal(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "test-envdir-*")
+	if err != nil {
+		t.Fatalf("unexpected error in TempDir: %v", err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	ready := filepath.Join(dir, "ready")
+
+	// The helper stays in the process group of the test, like a command
+	// attached to a terminal.
+	command := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	command.Env = append(os.Environ(), helperEnv+"=default", "GO_ENVDIR_HELPER_READY="+ready)
+
+	if err := command.Start(); err != nil {
+		t.Fatalf("unexpected error in Start: %v", err)
+	}
+
+	waitReady(t, ready)
+
+	exited := make(chan error, 1)
+
+	go func() {
+		exited <- command.Wait()
+	}()
+
+	// SIGINT is sent by the terminal itself, so it isn't forwarded.
+	forwardSignal(command.Process.Pid, false, syscall.SIGINT)
+
+	select {
+	case err := <-exited:
+		t.Fatalf("terminal signal is forwarded, helper exited: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	forwardSignal(command.Process.Pid, false, syscall.SIGTERM)
+
+	select {
+	case err := <-exited:
+		expected := signalExitCodeBase + int(syscall.SIGTERM)
+		if code := exitCode(err); code != expected {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, expected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("helper isn't finished after signal")
+	}
+}
+
+// runHelper runs TestHelperProcess in mode by RunCmd, sends sig to the test
+// process when the helper is ready and returns exit code of RunCmd.
+func runHelper(t *testing.T, mode string, sig syscall.Signal) int {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("/tmp", "test-envdir-*")
+	if err != nil {
+		t.Fatalf("unexpected error in TempDir: %v", err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	ready := filepath.Join(dir, "ready")
+	env := Environment{
+		helperEnv:                {Value: mode},
+		"GO_ENVDIR_HELPER_READY": {Value: ready},
+	}
+
+	codes := make(chan int, 1)
+
+	go func() {
+		codes <- RunCmd([]string{os.Args[0], "-test.run=^TestHelperProcess$"}, env)
+	}()
+
+	waitReady(t, ready)
+
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		t.Fatalf("unexpected error in Kill: %v", err)
+	}
+
+	select {
+	case code := <-codes:
+		return code
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunCmd isn't finished after signal")
+	}
+
+	return 0
+}
+
+// waitReady waits until helper process creates file ready.
+func waitReady(t *testing.T, ready string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if _, err := os.Stat(ready); err == nil {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("helper process isn't ready")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
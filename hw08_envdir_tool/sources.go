@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sourceList is a flag of env sources, which may be repeated.
+type sourceList []string
+
+func (l *sourceList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *sourceList) Set(path string) error {
+	*l = append(*l, path)
+	return nil
+}
+
+// ReadSources reads env variables from directories and dotenv files
+// in order. Variables of later sources override variables of earlier ones,
+// including removal of variables by empty files.
+func ReadSources(paths ...string) (Environment, error) {
+	env := make(Environment)
+
+	for _, path := range paths {
+		source, err := readSource(path)
+		if err != nil {
+			return nil, err
+		}
+
+		env.Merge(source)
+	}
+
+	return env, nil
+}
+
+// readSource reads env variables from directory by ReadDir
+// or from dotenv file by ReadEnvFile.
+func readSource(path string) (Environment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read env source: %w", err)
+	}
+
+	if info.IsDir() {
+		return ReadDir(path)
+	}
+
+	return ReadEnvFile(path)
+}
+
+// Merge sets variables from other into e, replacing existing ones.
+func (e Environment) Merge(other Environment) {
+	for name, value := range other {
+		e[name] = value
+	}
+}
+
+// PrintEnv writes variables of env sorted by name to w with their sources.
+// Removed variables are written as "unset NAME".
+func PrintEnv(w io.Writer, env Environment) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := env[name]
+
+		var err error
+		if value.NeedRemove {
+			_, err = fmt.Fprintf(w, "unset %s\t# %s\n", name, value.Source)
+		} else {
+			_, err = fmt.Fprintf(w, "%s=%s\t# %s\n", name, strconv.Quote(value.Value), value.Source)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
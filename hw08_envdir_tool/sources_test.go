@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadSources(t *testing.T) {
+	t.Run("Override", func(t *testing.T) {
+		base := tempEnvDir(t, map[string]string{
+			"FOO":   "base",
+			"BAR":   "base",
+			"UNSET": "base",
+		})
+		file := tempEnvFile(t, "BAR=file\nBAZ=file\n")
+		secrets := tempEnvDir(t, map[string]string{
+			"BAZ":   "secret",
+			"UNSET": "",
+		})
+
+		env, err := ReadSources(base, file, secrets)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadSources: %v", err)
+		}
+
+		expected := Environment{
+			"FOO":   {Value: "base", Source: filepath.Join(base, "FOO")},
+			"BAR":   {Value: "file", Source: file + ":1"},
+			"BAZ":   {Value: "secret", Source: filepath.Join(secrets, "BAZ")},
+			"UNSET": {NeedRemove: true, Source: filepath.Join(secrets, "UNSET")},
+		}
+
+		if !reflect.DeepEqual(env, expected) {
+			t.Fatalf("unexpected environment: %v, expected: %v", env, expected)
+		}
+	})
+
+	t.Run("MissingSource", func(t *testing.T) {
+		if _, err := ReadSources("./testdata/env", "./testdata/missing"); err == nil {
+			t.Fatal("expected error in ReadSources")
+		}
+	})
+}
+
+func TestSourceList(t *testing.T) {
+	var sources sourceList
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&sources, "source", "")
+
+	if err := flags.Parse([]string{"-source", "/etc/env:prod", "-source", ".env", "DIR"}); err != nil {
+		t.Fatalf("unexpected error in Parse: %v", err)
+	}
+
+	expected := sourceList{"/etc/env:prod", ".env"}
+
+	if !reflect.DeepEqual(sources, expected) {
+		t.Fatalf("unexpected sources: %v, expected: %v", sources, expected)
+	}
+}
+
+func TestPrintEnv(t *testing.T) {
+	env := Environment{
+		"FOO":   {Value: "foo\nbar", Source: "base/FOO"},
+		"BAR":   {Value: "bar", Source: "prod.env:3"},
+		"UNSET": {NeedRemove: true, Source: "base/UNSET"},
+	}
+
+	var out bytes.Buffer
+	if err := PrintEnv(&out, env); err != nil {
+		t.Fatalf("unexpected error in PrintEnv: %v", err)
+	}
+
+	expected := "BAR=\"bar\"\t# prod.env:3\n" +
+		"FOO=\"foo\\nbar\"\t# base/FOO\n" +
+		"unset UNSET\t# base/UNSET\n"
+
+	if out.String() != expected {
+		t.Fatalf("unexpected output: %q, expected: %q", out.String(), expected)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// settleDelay is a time to wait after a change of sources before reloading
+// them, so files updated one by one are reloaded together.
+const settleDelay = 200 * time.Millisecond
+
+// WatchOptions configures restarting of the command in watch mode.
+type WatchOptions struct {
+	// Paths are env sources to watch.
+	Paths []string
+	// Signal is sent to the command to stop it before restart.
+	Signal syscall.Signal
+	// Grace is a time to wait for the command to stop after Signal
+	// before it is killed.
+	Grace time.Duration
+	// Interval is a polling interval used if inotify is unavailable.
+	Interval time.Duration
+}
+
+// WatchCmd runs a command + arguments (cmd) with environment variables
+// from env like RunCmd, but restarts it with environment returned by load
+// when sources in opts are changed. If the environment can't be loaded,
+// the command keeps running. The exit code of the command is returned
+// when it exits by itself or is stopped by forwarded SIGINT or SIGTERM.
+func WatchCmd(cmd []string, env Environment, load func() (Environment, error), opts WatchOptions) int {
+	forwarder := newSignalForwarder()
+	defer forwarder.stop()
+
+	w := newWatcher(opts.Paths, opts.Interval)
+	defer w.Close()
+
+	command, group, err := startCmd(cmd, env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedExitCode
+	}
+
+	var (
+		exited = waitCmd(command)
+		// next is the environment to restart the command with,
+		// it is not nil while the command is being stopped.
+		next     Environment
+		stopping bool
+		settle   <-chan time.Time
+		kill     <-chan time.Time
+	)
+
+	for {
+		select {
+		case err := <-exited:
+			if next == nil {
+				return exitCode(err)
+			}
+
+			if command, group, err = startCmd(cmd, next); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return failedExitCode
+			}
+
+			exited = waitCmd(command)
+			next, kill = nil, nil
+		case <-w.Changes():
+			if !stopping {
+				settle = time.After(settleDelay)
+			}
+		case <-settle:
+			settle = nil
+
+			env, err := load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "can't reload environment, command isn't restarted: %v\n", err)
+				continue
+			}
+
+			restarting := next != nil
+			next = env
+
+			if !restarting {
+				_ = signalProcess(command.Process.Pid, group, opts.Signal)
+				kill = time.After(opts.Grace)
+			}
+		case <-kill:
+			kill = nil
+
+			_ = signalProcess(command.Process.Pid, group, syscall.SIGKILL)
+		case sig := <-forwarder.signals:
+			forwardSignal(command.Process.Pid, group, sig)
+
+			if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+				stopping, next, settle = true, nil, nil
+			}
+		}
+	}
+}
+
+// waitCmd waits for command in background and returns channel
+// which receives the result.
+func waitCmd(command *exec.Cmd) <-chan error {
+	exited := make(chan error, 1)
+
+	go func() {
+		exited <- command.Wait()
+	}()
+
+	return exited
+}
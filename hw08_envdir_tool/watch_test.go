@@ -0,0 +1,185 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchers(t *testing.T) {
+	watchers := map[string]func(paths []string) (watcher, error){
+		"Inotify": func(paths []string) (watcher, error) {
+			return newInotifyWatcher(paths)
+		},
+		"Poll": func(paths []string) (watcher, error) {
+			return newPollWatcher(paths, 10*time.Millisecond), nil
+		},
+	}
+
+	for name, newWatcher := range watchers {
+		newWatcher := newWatcher
+
+		t.Run(name, func(t *testing.T) {
+			dir := tempEnvDir(t, map[string]string{"FOO": "foo"})
+			file := tempEnvFile(t, "BAR=bar\n")
+
+			w, err := newWatcher([]string{dir, file})
+			if err != nil {
+				t.Skipf("watcher is unavailable: %v", err)
+			}
+
+			defer w.Close()
+
+			// The poll watcher distinguishes modification times,
+			// which may be too coarse for changes made right after creation.
+			time.Sleep(20 * time.Millisecond)
+
+			writeFile(t, filepath.Join(dir, "FOO"), "changed")
+			requireChange(t, w)
+
+			writeFile(t, filepath.Join(dir, "BAZ"), "new")
+			requireChange(t, w)
+
+			writeFile(t, file, "BAR=changed\n")
+			requireChange(t, w)
+		})
+	}
+}
+
+func TestInotifyWatcherIgnoresSiblings(t *testing.T) {
+	file := tempEnvFile(t, "BAR=bar\n")
+	sibling := tempEnvFile(t, "BAZ=baz\n")
+
+	w, err := newInotifyWatcher([]string{file})
+	if err != nil {
+		t.Skipf("inotify is unavailable: %v", err)
+	}
+
+	defer w.Close()
+
+	writeFile(t, sibling, "BAZ=changed\n")
+
+	select {
+	case <-w.Changes():
+		t.Fatal("unexpected change of unwatched file")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchCmd(t *testing.T) {
+	dir := tempEnvDir(t, map[string]string{"FOO": "1"})
+	out := filepath.Join(dir, "..", filepath.Base(dir)+".out")
+
+	t.Cleanup(func() { os.Remove(out) })
+
+	load := func() (Environment, error) { return ReadDir(dir) }
+
+	env, err := load()
+	if err != nil {
+		t.Fatalf("unexpected error in ReadDir: %v", err)
+	}
+
+	opts := WatchOptions{
+		Paths:    []string{dir},
+		Signal:   syscall.SIGTERM,
+		Grace:    time.Second,
+		Interval: 10 * time.Millisecond,
+	}
+
+	cmd := []string{"/bin/sh", "-c", `echo "$FOO" >> "$0"; exec sleep 10`, out}
+	codes := make(chan int, 1)
+
+	go func() {
+		codes <- WatchCmd(cmd, env, load, opts)
+	}()
+
+	waitOutput(t, out, "1\n")
+
+	writeFile(t, filepath.Join(dir, "FOO"), "2")
+	waitOutput(t, out, "1\n2\n")
+
+	// Broken environment doesn't stop the running command.
+	writeFile(t, filepath.Join(dir, "BAR=BAZ"), "3")
+	time.Sleep(2 * settleDelay)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("unexpected error in Kill: %v", err)
+	}
+
+	select {
+	case code := <-codes:
+		expected := signalExitCodeBase + int(syscall.SIGTERM)
+		if code != expected {
+			t.Fatalf("unexpected exit code: %d, expected: %d", code, expected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchCmd isn't finished after signal")
+	}
+
+	waitOutput(t, out, "1\n2\n")
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := map[string]syscall.Signal{
+		"TERM":   syscall.SIGTERM,
+		"sighup": syscall.SIGHUP,
+		"USR1":   syscall.SIGUSR1,
+		"9":      syscall.SIGKILL,
+	}
+
+	for s, expected := range tests {
+		sig, err := ParseSignal(s)
+		if err != nil {
+			t.Fatalf("unexpected error in ParseSignal(%q): %v", s, err)
+		}
+
+		if sig != expected {
+			t.Fatalf("unexpected signal for %q: %v, expected: %v", s, sig, expected)
+		}
+	}
+
+	if _, err := ParseSignal("SIGFOO"); err == nil {
+		t.Fatal("expected error in ParseSignal")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error in WriteFile: %v", err)
+	}
+}
+
+func requireChange(t *testing.T, w watcher) {
+	t.Helper()
+
+	select {
+	case <-w.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("change isn't reported")
+	}
+}
+
+// waitOutput waits until file name contains expected.
+func waitOutput(t *testing.T, name, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		content, _ := ioutil.ReadFile(name)
+		if string(content) == expected {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected output: %q, expected: %q", content, expected)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
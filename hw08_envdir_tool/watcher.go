@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// watcher reports changes of env sources.
+type watcher interface {
+	// Changes returns channel which receives a value after sources are changed.
+	// Several changes may be reported by one value.
+	Changes() <-chan struct{}
+	Close() error
+}
+
+// newWatcher returns inotify watcher of paths if it is available,
+// else watcher which polls paths every interval.
+func newWatcher(paths []string, interval time.Duration) watcher {
+	if w, err := newInotifyWatcher(paths); err == nil {
+		return w
+	}
+
+	return newPollWatcher(paths, interval)
+}
+
+// notify sends a value into changes if it doesn't have one already.
+func notify(changes chan struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// pollWatcher compares snapshots of sources taken every interval.
+type pollWatcher struct {
+	changes chan struct{}
+	done    chan struct{}
+}
+
+func newPollWatcher(paths []string, interval time.Duration) *pollWatcher {
+	w := &pollWatcher{
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	go w.poll(paths, interval)
+
+	return w
+}
+
+func (w *pollWatcher) poll(paths []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := snapshot(paths)
+
+	for {
+		select {
+		case <-ticker.C:
+			if current := snapshot(paths); current != last {
+				last = current
+
+				notify(w.changes)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *pollWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *pollWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+// snapshot returns names, sizes, modes and modification times
+// of paths and files in them, if they are directories.
+func snapshot(paths []string) string {
+	var b strings.Builder
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", path, err)
+			continue
+		}
+
+		writeFileInfo(&b, path, info)
+
+		if !info.IsDir() {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", path, err)
+			continue
+		}
+
+		for _, file := range files {
+			writeFileInfo(&b, path, file)
+		}
+	}
+
+	return b.String()
+}
+
+func writeFileInfo(b *strings.Builder, dir string, info os.FileInfo) {
+	fmt.Fprintf(b, "%s/%s %d %v %d\n", dir, info.Name(), info.Size(), info.Mode(), info.ModTime().UnixNano())
+}
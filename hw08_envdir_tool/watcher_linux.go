@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask is a set of events which may change env sources.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher watches env sources by inotify(7). Directories are watched
+// directly, dotenv files are watched by their parent directories,
+// so files replaced by rename are watched too.
+type inotifyWatcher struct {
+	file    *os.File
+	changes chan struct{}
+	// names are names of watched files in directories by watch descriptors,
+	// empty name means any file of the directory.
+	names map[int32][]string
+}
+
+func newInotifyWatcher(paths []string) (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("can't init inotify: %w", err)
+	}
+
+	// Non-blocking file is closed by Close even when it is being read.
+	w := &inotifyWatcher{
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		changes: make(chan struct{}, 1),
+		names:   make(map[int32][]string),
+	}
+
+	for _, path := range paths {
+		dir, name := path, ""
+
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir, name = filepath.Dir(path), filepath.Base(path)
+		}
+
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			w.file.Close()
+			return nil, fmt.Errorf("can't watch %s: %w", dir, err)
+		}
+
+		w.names[int32(wd)] = append(w.names[int32(wd)], name)
+	}
+
+	go w.read()
+
+	return w, nil
+}
+
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset])) //nolint:gosec
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+int(event.Len)], "\x00"))
+			offset = nameStart + int(event.Len)
+
+			if w.matches(event.Wd, name) {
+				notify(w.changes)
+			}
+		}
+	}
+}
+
+// matches reports whether event about file name in directory wd
+// is an event about watched source.
+func (w *inotifyWatcher) matches(wd int32, name string) bool {
+	for _, watched := range w.names[wd] {
+		// Events without name are events about the directory itself.
+		if watched == "" || name == "" || watched == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *inotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *inotifyWatcher) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+// newInotifyWatcher returns error, because inotify is available only on Linux,
+// so sources are polled.
+func newInotifyWatcher([]string) (watcher, error) {
+	return nil, errors.New("inotify is unsupported")
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"unicode"
+)
+
+// outputSuffix follows package name in name of generated file.
+const outputSuffix = "_validation_generated.go"
+
+var fileTemplate = template.Must(template.New("file").Parse(`
+// Code generated by go-validate; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"errors"
+	"fmt"
+{{- if .Regexps}}
+	"regexp"
+{{- end}}
+{{- if .UTF8}}
+	"unicode/utf8"
+{{- end}}
+)
+
+// Errors of values, they are wrapped into ValidationError.
+var (
+	ErrLen    = errors.New("invalid length")
+	ErrRegexp = errors.New("doesn't match regexp")
+	ErrIn     = errors.New("isn't in allowed set")
+	ErrMin    = errors.New("less than minimum")
+	ErrMax    = errors.New("greater than maximum")
+)
+{{- if eq (len .Regexps) 1}}
+{{- with index .Regexps 0}}
+
+var {{.Name}} = regexp.MustCompile({{printf "%q" .Pattern}})
+{{- end}}
+{{- else if .Regexps}}
+
+var (
+{{- range .Regexps}}
+	{{.Name}} = regexp.MustCompile({{printf "%q" .Pattern}})
+{{- end}}
+)
+{{- end}}
+
+// ValidationError is an error of struct field validation.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", v.Field, v.Err)
+}
+
+func (v ValidationError) Unwrap() error {
+	return v.Err
+}
+{{range .Structs}}
+// Validate validates fields of {{.Name}} by their validate tags.
+func ({{.Receiver}} {{.Name}}) Validate() ([]ValidationError, error) {
+	var errs []ValidationError
+{{- if .Nested}}
+
+	var (
+		nested []ValidationError
+		err    error
+	)
+{{- end}}
+{{range .Fields}}
+{{- if .Collection}}
+	for idx, elem := range {{.Value}} {
+{{- template "checks" .Elem}}
+	}
+{{else}}
+{{- template "checks" .Elem}}
+{{end}}
+{{- end}}
+	return errs, nil
+}
+{{end}}
+{{- define "checks"}}
+{{- range $i, $check := .Checks}}
+{{- if $i}}
+{{end}}
+	if {{$check.Cond}} {
+		errs = append(errs, ValidationError{Field: {{$.Path}}, Err: {{$check.Err}}})
+	}
+{{- end}}
+{{- if .Nested}}
+{{- if .Checks}}
+{{end}}
+	if nested, err = {{.Value}}.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, nestedErr := range nested {
+		nestedErr.Field = {{.Path}} + "." + nestedErr.Field
+		errs = append(errs, nestedErr)
+	}
+{{- end}}
+{{- end}}`))
+
+type fileData struct {
+	Package string
+	UTF8    bool
+	Regexps []regexpVar
+	Structs []structData
+}
+
+type structData struct {
+	Name     string
+	Receiver string
+	// Nested is set if any field is validated by its own Validate method.
+	Nested bool
+	Fields []fieldData
+}
+
+type fieldData struct {
+	// Value is an expression of field value.
+	Value string
+	// Collection is set if elements of the field are validated.
+	Collection bool
+	Elem       elemData
+}
+
+// elemData is a validated value, field or element of collection.
+type elemData struct {
+	// Value is an expression of validated value.
+	Value string
+	// Path is an expression of value name in validation errors.
+	Path   string
+	Checks []checkData
+	Nested bool
+}
+
+type checkData struct {
+	Cond string
+	Err  string
+}
+
+// generate generates file with Validate methods for structs of Go package
+// in directory path or of Go file at path. The generated file is placed in
+// the package directory and named by the package with outputSuffix.
+// It returns name of the generated file.
+func generate(path string) (string, error) {
+	dir := path
+	if fi, err := os.Stat(path); err != nil {
+		return "", err
+	} else if !fi.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	info, err := parsePackage(dir)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := render(info)
+	if err != nil {
+		return "", err
+	}
+
+	out := filepath.Join(dir, info.name+outputSuffix)
+
+	return out, ioutil.WriteFile(out, src, 0o644) //nolint:gosec
+}
+
+// render returns formatted source of Validate methods for structs of info.
+func render(info packageInfo) ([]byte, error) {
+	c := compiler{structs: make(map[string]bool, len(info.structs))}
+	data := fileData{Package: info.name}
+
+	for _, s := range info.structs {
+		c.structs[s.name] = true
+	}
+
+	for _, s := range info.structs {
+		sd := structData{Name: s.name, Receiver: receiverName(s.name)}
+
+		for _, f := range s.fields {
+			checks, nested, err := c.compileField(s.name, f)
+			if err != nil {
+				return nil, err
+			}
+
+			fd := fieldData{
+				Value:      sd.Receiver + "." + f.name,
+				Collection: f.typ.collection,
+				Elem:       elemData{Value: sd.Receiver + "." + f.name, Path: strconv.Quote(f.name), Nested: nested},
+			}
+
+			if fd.Collection {
+				fd.Elem.Value = "elem"
+				fd.Elem.Path = fmt.Sprintf("fmt.Sprintf(%q, idx)", f.name+"[%d]")
+			}
+
+			for _, ch := range checks {
+				fd.Elem.Checks = append(fd.Elem.Checks, checkData{Cond: ch.cond(fd.Elem.Value), Err: ch.err})
+			}
+
+			sd.Nested = sd.Nested || nested
+			sd.Fields = append(sd.Fields, fd)
+		}
+
+		data.Structs = append(data.Structs, sd)
+	}
+
+	data.UTF8, data.Regexps = c.utf8, c.regexps
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return format.Source(bytes.TrimLeft(buf.Bytes(), "\n"))
+}
+
+// receiverName returns name of receiver of Validate method for struct name.
+// It is a single letter, so it doesn't collide with longer local variables
+// of generated methods.
+func receiverName(name string) string {
+	for _, r := range name {
+		return string(unicode.ToLower(r))
+	}
+
+	return ""
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"user.go": `package models
+
+type User struct {
+	ID      string  ` + "`validate:\"len:36|regexp:^[0-9a-f-]+$\"`" + `
+	Roles   []Role  ` + "`validate:\"in:admin,stuff\"`" + `
+	Age     int8    ` + "`validate:\"min:18|max:120\"`" + `
+	Codes   [2]int  ` + "`validate:\"in:-1,1\"`" + `
+	Friends []User  ` + "`validate:\"nested\"`" + `
+	Balance Money   ` + "`validate:\"nested\"`" + `
+	Address ` + "`validate:\"nested\"`" + `
+}
+
+type Money struct {
+	Amount int
+}
+
+func (m *Money) Validate() ([]ValidationError, error) {
+	return nil, nil
+}
+`,
+		"address.go": `package models
+
+type (
+	Role string
+
+	Address struct {
+		Zip string ` + "`validate:\"regexp:^\\\\d{6}$\"`" + `
+	}
+)
+
+func (a Address) IsValid() bool {
+	errs, err := a.Validate()
+	return len(errs) == 0 && err == nil
+}
+`,
+	})
+
+	// Generated file of previous run is replaced.
+	for _, path := range []string{dir, filepath.Join(dir, "user.go"), dir} {
+		out, err := generate(path)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "models_validation_generated.go"), out)
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	require.NoError(t, err)
+	require.Len(t, pkgs["models"].Files, 3)
+
+	files := make([]*ast.File, 0, 3)
+	for _, f := range pkgs["models"].Files {
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("models", fset, files, nil)
+	require.NoError(t, err)
+
+	require.True(t, ast.IsGenerated(pkgs["models"].Files[filepath.Join(dir, "models_validation_generated.go")]))
+
+	t.Run("invalid rule", func(t *testing.T) {
+		dir := writePackage(t, map[string]string{
+			"models.go": "package models\n\ntype S struct {\n\tF int `validate:\"min:x\"`\n}\n",
+		})
+
+		_, err := generate(dir)
+		require.True(t, errors.Is(err, ErrInvalidRuleArg), err)
+		require.Equal(t, filepath.Join(dir, "models.go")+`:4:8: S.F: rule "min:x": invalid rule argument`, err.Error())
+
+		_, err = os.Stat(filepath.Join(dir, "models_validation_generated.go"))
+		require.True(t, os.IsNotExist(err), err)
+	})
+
+	t.Run("nested without Validate", func(t *testing.T) {
+		for _, typ := range []string{"Inner", "time.Time"} {
+			dir := writePackage(t, map[string]string{
+				"models.go": "package models\n\nimport \"time\"\n\nvar _ time.Time\n\ntype Inner struct {\n\tF int\n}\n\n" +
+					"type S struct {\n\tIn " + typ + " `validate:\"nested\"`\n}\n",
+			})
+
+			_, err := generate(dir)
+			require.True(t, errors.Is(err, ErrUnsupportedType), "%s: %v", typ, err)
+			require.Equal(t, filepath.Join(dir, "models.go")+`:12:`+strconv.Itoa(6+len(typ))+`: S.In: rule "nested": `+
+				"unsupported type "+typ+" without Validate method", err.Error())
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := generate(filepath.Join(dir, "missing.go"))
+		require.True(t, os.IsNotExist(err), err)
+	})
+}
+
+func TestRender(t *testing.T) {
+	pos := token.Position{Filename: "models.go", Line: 3, Column: 9}
+	user := func(typ fieldType, tag string) packageInfo {
+		return packageInfo{name: "models", structs: []structInfo{{
+			name:   "User",
+			fields: []fieldInfo{{name: "F", typ: typ, tag: tag, pos: pos}},
+		}}}
+	}
+
+	str := fieldType{kind: kindString}
+	num := fieldType{kind: kindInt, bits: 8}
+	nested := fieldType{kind: kindStruct, name: "User"}
+
+	t.Run("checks", func(t *testing.T) {
+		tests := []struct {
+			typ      fieldType
+			tag      string
+			expected string
+		}{
+			{typ: str, tag: "len:036", expected: "if utf8.RuneCountInString(string(u.F)) != 36 {"},
+			{typ: str, tag: `in:a,"b%d"`, expected: `if u.F != "a" && u.F != "\"b%d\"" {`},
+			{typ: str, tag: `regexp:\d+`, expected: `var userFRegexp = regexp.MustCompile("\\d+")`},
+			{typ: str, tag: `regexp:\d+|regexp:\w+`, expected: "var (\n\tuserFRegexp  = regexp.MustCompile"},
+			{typ: str, tag: `regexp:\d+|regexp:\w+`, expected: `userFRegexp2 = regexp.MustCompile("\\w+")`},
+			{typ: num, tag: "min:-010|max:+10", expected: "if u.F < -10 {"},
+			{typ: num, tag: "min:-010|max:+10", expected: "\t}\n\n\tif u.F > 10 {"},
+			{typ: num, tag: "in:1,2", expected: "if u.F != 1 && u.F != 2 {"},
+			{
+				typ:      fieldType{kind: kindInt, bits: 8, collection: true},
+				tag:      "max:10",
+				expected: `ValidationError{Field: fmt.Sprintf("F[%d]", idx), Err: fmt.Errorf("%w: %s", ErrMax, "10")}`,
+			},
+			{typ: nested, tag: "nested", expected: `nestedErr.Field = "F" + "." + nestedErr.Field`},
+			{
+				typ:      fieldType{kind: kindStruct, name: "Money", validate: true},
+				tag:      "nested",
+				expected: "if nested, err = u.F.Validate(); err != nil {",
+			},
+		}
+
+		for _, tt := range tests {
+			src, err := render(user(tt.typ, tt.tag))
+			require.NoError(t, err, tt.tag)
+			require.Contains(t, string(src), tt.expected, tt.tag)
+		}
+	})
+
+	t.Run("receiver", func(t *testing.T) {
+		info := user(fieldType{kind: kindString, collection: true}, "len:1")
+		info.structs[0].name = "Item"
+
+		src, err := render(info)
+		require.NoError(t, err)
+		require.Contains(t, string(src), "func (i Item) Validate() ([]ValidationError, error) {")
+		require.Contains(t, string(src), "for idx, elem := range i.F {")
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		tests := []struct {
+			typ         fieldType
+			tag         string
+			expectedErr error
+		}{
+			{typ: str, tag: "len:-1", expectedErr: ErrInvalidRuleArg},
+			{typ: str, tag: "len:x", expectedErr: ErrInvalidRuleArg},
+			{typ: str, tag: "regexp:[", expectedErr: ErrInvalidRuleArg},
+			{typ: str, tag: "min:1", expectedErr: ErrUnknownRule},
+			{typ: str, tag: "nested", expectedErr: ErrUnknownRule},
+			{typ: str, tag: "len:1|", expectedErr: ErrUnknownRule},
+			{typ: num, tag: "min:1000", expectedErr: ErrInvalidRuleArg},
+			{typ: num, tag: "max:1,2", expectedErr: ErrInvalidRuleArg},
+			{typ: num, tag: "in:1,", expectedErr: ErrInvalidRuleArg},
+			{typ: num, tag: "len:1", expectedErr: ErrUnknownRule},
+			{typ: nested, tag: "nested:1", expectedErr: ErrUnknownRule},
+			{typ: fieldType{kind: kindStruct, name: "time.Time"}, tag: "nested", expectedErr: ErrUnsupportedType},
+		}
+
+		for _, tt := range tests {
+			_, err := render(user(tt.typ, tt.tag))
+			require.True(t, errors.Is(err, tt.expectedErr), "%s: %v", tt.tag, err)
+			require.True(t, strings.HasPrefix(err.Error(), "models.go:3:9: User.F: rule "), err.Error())
+		}
+	})
+}
@@ -1,5 +1,31 @@
 package main
 
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: go-validate [PATH]
+Generate Validate methods for structs with validate tags of Go package
+in directory PATH or containing file PATH into PACKAGE_validation_generated.go.
+PATH is the current directory by default.
+
+Example:
+	//go:generate go-validate`
+
 func main() {
-	// Place your code here
+	if len(os.Args) > 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	path := "."
+	if len(os.Args) == 2 {
+		path = os.Args[1]
+	}
+
+	if _, err := generate(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
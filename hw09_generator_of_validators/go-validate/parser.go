@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrInvalidTag      = errors.New("invalid tag")
+	ErrRedeclared      = errors.New("generated identifier is already declared")
+)
+
+// generatedNames are package level identifiers declared by fileTemplate
+// in every generated file.
+var generatedNames = []string{"ValidationError", "ErrLen", "ErrRegexp", "ErrIn", "ErrMin", "ErrMax"}
+
+// validateTag is a name of struct tag with validation rules.
+const validateTag = "validate"
+
+// kind is a kind of validated values the generator supports.
+type kind int
+
+const (
+	kindUnknown kind = iota
+	kindString
+	kindInt
+	kindStruct
+)
+
+// intSizes are bit sizes of supported integer types.
+var intSizes = map[types.BasicKind]int{
+	types.Int:   strconv.IntSize,
+	types.Int8:  8,
+	types.Int16: 16,
+	types.Int32: 32,
+	types.Int64: 64,
+}
+
+// fieldType is a resolved type of struct field.
+type fieldType struct {
+	kind kind
+	// bits is a bit size of integer types.
+	bits int
+	// collection is set for slices and arrays, whose elements are validated.
+	collection bool
+	// name is a name of struct type, qualified if it's declared in another package.
+	name string
+	// validate is set if struct type has Validate method like generated one.
+	validate bool
+}
+
+// fieldInfo is a struct field with validate tag.
+type fieldInfo struct {
+	name string
+	typ  fieldType
+	tag  string
+	// pos is a position of the tag in source file.
+	pos token.Position
+}
+
+// structInfo is a struct type with validated fields.
+type structInfo struct {
+	name   string
+	fields []fieldInfo
+}
+
+// packageInfo is a parsed Go package.
+type packageInfo struct {
+	name    string
+	structs []structInfo
+}
+
+// parsePackage parses Go package in dir and returns structs having fields
+// with validate tags. Test files and files generated by go-validate are
+// skipped. Structs are returned in order of declaration in files sorted
+// by name, including several structs declared in one type (...) block.
+// Named field types are resolved to their underlying types with go/types.
+func parsePackage(dir string) (packageInfo, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return packageInfo{}, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+
+	for _, name := range bp.GoFiles {
+		if strings.HasSuffix(name, outputSuffix) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return packageInfo{}, err
+		}
+
+		files = append(files, f)
+	}
+
+	// Type errors are ignored, as the package may use Validate methods
+	// which aren't generated yet. Types of fields are resolved anyway.
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil), Error: func(error) {}}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, info)
+
+	if err := checkGeneratedNames(fset, pkg); err != nil {
+		return packageInfo{}, err
+	}
+
+	p := packageInfo{name: bp.Name}
+	r := resolver{fset: fset, info: info, pkg: pkg}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+
+				s, err := r.parseStruct(ts.Name.Name, st)
+				if err != nil {
+					return packageInfo{}, err
+				}
+
+				if len(s.fields) > 0 {
+					p.structs = append(p.structs, s)
+				}
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// checkGeneratedNames returns error if pkg already declares any of
+// generatedNames, as the generated file wouldn't compile.
+func checkGeneratedNames(fset *token.FileSet, pkg *types.Package) error {
+	for _, name := range generatedNames {
+		if obj := pkg.Scope().Lookup(name); obj != nil {
+			return fmt.Errorf("%s: %w %s", fset.Position(obj.Pos()), ErrRedeclared, name)
+		}
+	}
+
+	return nil
+}
+
+// resolver resolves types of struct fields by type information of package.
+type resolver struct {
+	fset *token.FileSet
+	info *types.Info
+	pkg  *types.Package
+}
+
+// parseStruct returns fields of st with validate tags.
+// Embedded fields are named by their type.
+func (r resolver) parseStruct(name string, st *ast.StructType) (structInfo, error) {
+	s := structInfo{name: name}
+
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+
+		pos := r.fset.Position(field.Tag.Pos())
+
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return structInfo{}, fmt.Errorf("%s: %s: %w %s", pos, name, ErrInvalidTag, field.Tag.Value)
+		}
+
+		// Lookup fails on malformed tags, like validate:min:1 without quotes.
+		tag, ok := reflect.StructTag(raw).Lookup(validateTag)
+		if !ok && strings.Contains(raw, validateTag+":") {
+			return structInfo{}, fmt.Errorf("%s: %s: %w %s", pos, name, ErrInvalidTag, field.Tag.Value)
+		}
+
+		if tag == "" {
+			continue
+		}
+
+		names := make([]string, 0, len(field.Names))
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+
+		if len(names) == 0 {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		typ, err := r.resolveType(r.info.TypeOf(field.Type))
+		if err != nil {
+			return structInfo{}, fmt.Errorf("%s: %s.%s: %w", pos, name, names[0], err)
+		}
+
+		for _, n := range names {
+			s.fields = append(s.fields, fieldInfo{name: n, typ: typ, tag: tag, pos: pos})
+		}
+	}
+
+	return s, nil
+}
+
+// resolveType returns field type of t by its underlying type.
+func (r resolver) resolveType(t types.Type) (fieldType, error) {
+	if t == nil {
+		return fieldType{}, ErrUnsupportedType
+	}
+
+	var elem types.Type
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.String {
+			return fieldType{kind: kindString}, nil
+		}
+
+		if bits, ok := intSizes[u.Kind()]; ok {
+			return fieldType{kind: kindInt, bits: bits}, nil
+		}
+	case *types.Struct:
+		return fieldType{kind: kindStruct, name: types.TypeString(t, types.RelativeTo(r.pkg)), validate: r.hasValidate(t)}, nil
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Array:
+		elem = u.Elem()
+	}
+
+	if elem != nil {
+		if typ, err := r.resolveType(elem); err == nil && !typ.collection {
+			typ.collection = true
+			return typ, nil
+		}
+	}
+
+	return fieldType{}, fmt.Errorf("%w %s", ErrUnsupportedType, types.TypeString(t, types.RelativeTo(r.pkg)))
+}
+
+// hasValidate reports whether type t has method Validate() ([]ValidationError, error),
+// where ValidationError is declared in the package. The type is invalid if it's
+// declared only in the generated file, which isn't parsed.
+func (r resolver) hasValidate(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, r.pkg, "Validate")
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 2 ||
+		!types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type()) {
+		return false
+	}
+
+	errs, ok := sig.Results().At(0).Type().(*types.Slice)
+	if !ok {
+		return false
+	}
+
+	switch elem := errs.Elem().(type) {
+	case *types.Named:
+		return elem.Obj().Pkg() == r.pkg && elem.Obj().Name() == "ValidationError"
+	case *types.Basic:
+		return elem.Kind() == types.Invalid
+	}
+
+	return false
+}
+
+// embeddedName returns name of embedded field of type expr.
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+
+	return types.ExprString(expr)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writePackage writes Go files with sources by names into temporary directory
+// and returns its path.
+func writePackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "go-validate")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, src := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+
+	return dir
+}
+
+func TestParsePackage(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"user.go": `package models
+
+import "time"
+
+type (
+	User struct {
+		ID      string ` + "`json:\"id\" validate:\"len:36\"`" + `
+		Name    string ` + "`json:\"name\"`" + `
+		Roles   Roles  ` + "`validate:\"in:admin,stuff\"`" + `
+		Timeout time.Duration ` + "`validate:\"min:0\"`" + `
+		Code
+		Address ` + "`validate:\"nested\"`" + `
+	}
+
+	Address struct {
+		Zip, House []int ` + "`validate:\"min:1\"`" + `
+	}
+)
+
+func (u User) IsAdmin() bool {
+	// Validate is called before it's generated.
+	_, err := u.Validate()
+	return err == nil
+}
+`,
+		"types.go": `package models
+
+type (
+	Role  string
+	Roles []Role
+	Code  int16
+)
+
+type Token struct {
+	Payload []byte ` + "`validate:\"\"`" + `
+}
+`,
+		"user_test.go":                   "package models\n\ntype Test struct {\n\tF float64 `validate:\"min:1\"`\n}\n",
+		"models_validation_generated.go": "package models\n\ntype Generated struct {\n\tF float64 `validate:\"min:1\"`\n}\n",
+	})
+
+	info, err := parsePackage(dir)
+	require.NoError(t, err)
+
+	lines := make([]int, 0)
+
+	for _, s := range info.structs {
+		for i, f := range s.fields {
+			require.Equal(t, filepath.Join(dir, "user.go"), f.pos.Filename)
+			lines = append(lines, f.pos.Line)
+			s.fields[i].pos = token.Position{}
+		}
+	}
+
+	require.Equal(t, []int{7, 9, 10, 12, 16, 16}, lines)
+	require.Equal(t, packageInfo{
+		name: "models",
+		structs: []structInfo{
+			{name: "User", fields: []fieldInfo{
+				{name: "ID", typ: fieldType{kind: kindString}, tag: "len:36"},
+				{name: "Roles", typ: fieldType{kind: kindString, collection: true}, tag: "in:admin,stuff"},
+				{name: "Timeout", typ: fieldType{kind: kindInt, bits: 64}, tag: "min:0"},
+				{name: "Address", typ: fieldType{kind: kindStruct, name: "Address"}, tag: "nested"},
+			}},
+			{name: "Address", fields: []fieldInfo{
+				{name: "Zip", typ: fieldType{kind: kindInt, bits: strconv.IntSize, collection: true}, tag: "min:1"},
+				{name: "House", typ: fieldType{kind: kindInt, bits: strconv.IntSize, collection: true}, tag: "min:1"},
+			}},
+		},
+	}, info)
+
+	t.Run("unsupported types", func(t *testing.T) {
+		for _, typ := range []string{"float64", "*string", "[][]string", "map[string]int", "Loop", "Unknown"} {
+			dir := writePackage(t, map[string]string{
+				"models.go": "package models\n\ntype Loop Loop\n\ntype S struct {\n\tF " + typ + " `validate:\"min:1\"`\n}\n",
+			})
+
+			_, err := parsePackage(dir)
+			require.True(t, errors.Is(err, ErrUnsupportedType), "%s: %v", typ, err)
+			require.True(t, strings.HasPrefix(err.Error(), filepath.Join(dir, "models.go")+":6:"), err.Error())
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		for _, tag := range []string{"`validate:min:1`", "`json:\"f\" validate:\"min:1`", "`validate: \"min:1\"`"} {
+			dir := writePackage(t, map[string]string{
+				"models.go": "package models\n\ntype S struct {\n\tF int " + tag + "\n}\n",
+			})
+
+			_, err := parsePackage(dir)
+			require.True(t, errors.Is(err, ErrInvalidTag), "%s: %v", tag, err)
+			require.True(t, strings.HasPrefix(err.Error(), filepath.Join(dir, "models.go")+":4:8: S: "), err.Error())
+		}
+	})
+
+	t.Run("generated names", func(t *testing.T) {
+		for _, name := range generatedNames {
+			dir := writePackage(t, map[string]string{
+				"models.go": "package models\n\ntype S struct {\n\tF int `validate:\"min:1\"`\n}\n\nvar " + name + " error\n",
+			})
+
+			_, err := parsePackage(dir)
+			require.True(t, errors.Is(err, ErrRedeclared), "%s: %v", name, err)
+			require.Equal(t, filepath.Join(dir, "models.go")+":7:5: generated identifier is already declared "+name, err.Error())
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := parsePackage(writePackage(t, map[string]string{"models.go": "package models\n\ntype S struct {"}))
+		require.Error(t, err)
+	})
+
+	t.Run("no package", func(t *testing.T) {
+		_, err := parsePackage(writePackage(t, nil))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownRule    = errors.New("unknown rule")
+	ErrInvalidRuleArg = errors.New("invalid rule argument")
+)
+
+const (
+	ruleLen    = "len"
+	ruleRegexp = "regexp"
+	ruleIn     = "in"
+	ruleMin    = "min"
+	ruleMax    = "max"
+	ruleNested = "nested"
+)
+
+const (
+	// rulesSeparator separates rules combined by logical AND.
+	rulesSeparator = "|"
+	// argSeparator separates rule name and its argument.
+	argSeparator = ":"
+	// listSeparator separates items of "in" rule argument.
+	listSeparator = ","
+)
+
+// check is a generated check of one rule.
+type check struct {
+	// cond returns condition failing the check for expression of checked value.
+	cond func(value string) string
+	// err is an expression of validation error.
+	err string
+}
+
+// regexpVar is a package variable of compiled regexp.
+type regexpVar struct {
+	Name    string
+	Pattern string
+}
+
+// compiler compiles rules of validate tags into checks.
+type compiler struct {
+	// structs are names of structs which Validate methods are generated.
+	structs map[string]bool
+	regexps []regexpVar
+	// utf8 is set if checks use unicode/utf8 package.
+	utf8 bool
+}
+
+// compileField returns checks of rules in field tag and whether the field
+// is a nested struct validated by its own Validate method. The struct must
+// be one of generated structs or have Validate method like generated one.
+func (c *compiler) compileField(structName string, f fieldInfo) (checks []check, nested bool, err error) {
+	for _, r := range strings.Split(f.tag, rulesSeparator) {
+		name, arg := r, ""
+		if i := strings.Index(r, argSeparator); i >= 0 {
+			name, arg = r[:i], r[i+len(argSeparator):]
+		}
+
+		fail := func(err error) error {
+			return fmt.Errorf("%s: %s.%s: rule %q: %w", f.pos, structName, f.name, r, err)
+		}
+
+		if name == ruleNested && f.typ.kind == kindStruct && arg == "" {
+			if !c.structs[f.typ.name] && !f.typ.validate {
+				return nil, false, fail(fmt.Errorf("%w %s without Validate method", ErrUnsupportedType, f.typ.name))
+			}
+
+			nested = true
+
+			continue
+		}
+
+		ch, err := c.compileRule(structName, f, name, arg)
+		if err != nil {
+			return nil, false, fail(err)
+		}
+
+		checks = append(checks, ch)
+	}
+
+	return checks, nested, nil
+}
+
+// compileRule returns check of rule name with arg for field f.
+func (c *compiler) compileRule(structName string, f fieldInfo, name, arg string) (check, error) {
+	switch {
+	case f.typ.kind == kindString && name == ruleLen:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return check{}, ErrInvalidRuleArg
+		}
+
+		c.utf8 = true
+
+		return newCheck(func(v string) string {
+			return "utf8.RuneCountInString(string(" + v + ")) != " + strconv.Itoa(n)
+		}, "ErrLen", arg), nil
+	case f.typ.kind == kindString && name == ruleRegexp:
+		if _, err := regexp.Compile(arg); err != nil {
+			return check{}, fmt.Errorf("%w: %s", ErrInvalidRuleArg, err)
+		}
+
+		re := c.addRegexp(structName, f.name, arg)
+
+		return newCheck(func(v string) string {
+			return "!" + re + ".MatchString(string(" + v + "))"
+		}, "ErrRegexp", arg), nil
+	case f.typ.kind == kindString && name == ruleIn:
+		items := strings.Split(arg, listSeparator)
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+
+		return newCheck(notIn(items), "ErrIn", arg), nil
+	case f.typ.kind == kindInt && (name == ruleMin || name == ruleMax || name == ruleIn):
+		items := strings.Split(arg, listSeparator)
+		if name != ruleIn && len(items) > 1 {
+			return check{}, ErrInvalidRuleArg
+		}
+
+		values := make([]string, 0, len(items))
+
+		for _, item := range items {
+			n, err := strconv.ParseInt(item, 10, f.typ.bits)
+			if err != nil {
+				return check{}, ErrInvalidRuleArg
+			}
+
+			values = append(values, strconv.FormatInt(n, 10))
+		}
+
+		switch name {
+		case ruleMin:
+			return newCheck(func(v string) string { return v + " < " + values[0] }, "ErrMin", arg), nil
+		case ruleMax:
+			return newCheck(func(v string) string { return v + " > " + values[0] }, "ErrMax", arg), nil
+		default:
+			return newCheck(notIn(values), "ErrIn", arg), nil
+		}
+	}
+
+	return check{}, ErrUnknownRule
+}
+
+// addRegexp adds package variable of regexp for field and returns its name.
+func (c *compiler) addRegexp(structName, fieldName, pattern string) string {
+	r := []rune(structName)
+	r[0] = unicode.ToLower(r[0])
+	base := string(r) + fieldName + "Regexp"
+
+	name := base
+	for i := 2; c.hasRegexp(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	c.regexps = append(c.regexps, regexpVar{Name: name, Pattern: pattern})
+
+	return name
+}
+
+func (c *compiler) hasRegexp(name string) bool {
+	for _, v := range c.regexps {
+		if v.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCheck returns check with error wrapping sentinel error variable with rule argument.
+func newCheck(cond func(string) string, sentinel, arg string) check {
+	return check{cond: cond, err: fmt.Sprintf("fmt.Errorf(%q, %s, %q)", "%w: %s", sentinel, arg)}
+}
+
+// notIn returns condition of checked value not equal to any of values.
+func notIn(values []string) func(string) string {
+	return func(v string) string {
+		conds := make([]string, 0, len(values))
+		for _, value := range values {
+			conds = append(conds, v+" != "+value)
+		}
+
+		return strings.Join(conds, " && ")
+	}
+}
@@ -1,3 +1,5 @@
+//go:generate go-validate
+
 package models
 
 type UserRole string
@@ -3,6 +3,7 @@
 package models
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -60,8 +61,16 @@ func TestUserValidation(t *testing.T) {
 	})
 
 	t.Run("phones slice", func(t *testing.T) {
-		// Write me :)
-		t.Fail()
+		u := goodUser
+		u.Phones = []string{"79001234567", "89001234567"}
+		requireNoValidationErrors(t, u)
+
+		u.Phones = append(u.Phones, "123")
+
+		errs, err := u.Validate()
+		require.Nil(t, err)
+		requireOneFieldErr(t, errs, "Phones[2]")
+		require.True(t, errors.Is(errs[0].Err, ErrLen))
 	})
 
 	t.Run("many errors", func(t *testing.T) {
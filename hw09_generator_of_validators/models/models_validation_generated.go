@@ -0,0 +1,90 @@
+// Code generated by go-validate; DO NOT EDIT.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Errors of values, they are wrapped into ValidationError.
+var (
+	ErrLen    = errors.New("invalid length")
+	ErrRegexp = errors.New("doesn't match regexp")
+	ErrIn     = errors.New("isn't in allowed set")
+	ErrMin    = errors.New("less than minimum")
+	ErrMax    = errors.New("greater than maximum")
+)
+
+var userEmailRegexp = regexp.MustCompile("^\\w+@\\w+\\.\\w+$")
+
+// ValidationError is an error of struct field validation.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", v.Field, v.Err)
+}
+
+func (v ValidationError) Unwrap() error {
+	return v.Err
+}
+
+// Validate validates fields of User by their validate tags.
+func (u User) Validate() ([]ValidationError, error) {
+	var errs []ValidationError
+
+	if utf8.RuneCountInString(string(u.ID)) != 36 {
+		errs = append(errs, ValidationError{Field: "ID", Err: fmt.Errorf("%w: %s", ErrLen, "36")})
+	}
+
+	if u.Age < 18 {
+		errs = append(errs, ValidationError{Field: "Age", Err: fmt.Errorf("%w: %s", ErrMin, "18")})
+	}
+
+	if u.Age > 50 {
+		errs = append(errs, ValidationError{Field: "Age", Err: fmt.Errorf("%w: %s", ErrMax, "50")})
+	}
+
+	if !userEmailRegexp.MatchString(string(u.Email)) {
+		errs = append(errs, ValidationError{Field: "Email", Err: fmt.Errorf("%w: %s", ErrRegexp, "^\\w+@\\w+\\.\\w+$")})
+	}
+
+	if u.Role != "admin" && u.Role != "stuff" {
+		errs = append(errs, ValidationError{Field: "Role", Err: fmt.Errorf("%w: %s", ErrIn, "admin,stuff")})
+	}
+
+	for idx, elem := range u.Phones {
+		if utf8.RuneCountInString(string(elem)) != 11 {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("Phones[%d]", idx), Err: fmt.Errorf("%w: %s", ErrLen, "11")})
+		}
+	}
+
+	return errs, nil
+}
+
+// Validate validates fields of App by their validate tags.
+func (a App) Validate() ([]ValidationError, error) {
+	var errs []ValidationError
+
+	if utf8.RuneCountInString(string(a.Version)) != 5 {
+		errs = append(errs, ValidationError{Field: "Version", Err: fmt.Errorf("%w: %s", ErrLen, "5")})
+	}
+
+	return errs, nil
+}
+
+// Validate validates fields of Response by their validate tags.
+func (r Response) Validate() ([]ValidationError, error) {
+	var errs []ValidationError
+
+	if r.Code != 200 && r.Code != 404 && r.Code != 500 {
+		errs = append(errs, ValidationError{Field: "Code", Err: fmt.Errorf("%w: %s", ErrIn, "200,404,500")})
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,258 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errOverlap = errors.New("break overlaps event")
+
+type (
+	Event struct {
+		StartsAt time.Time  `validate:"required"`
+		EndsAt   time.Time  `validate:"gtfield:StartsAt"`
+		BreakAt  *time.Time `validate:"gtefield:StartsAt|ltefield:EndsAt"`
+		Min      int
+		Max      int    `validate:"gtefield:Min"`
+		Password string `validate:"minlen:8"`
+		Confirm  string `validate:"eqfield:Password"`
+		Login    string `validate:"nefield:Password"`
+		Role     UserRole
+		Phone    string `validate:"required_if:Role admin"`
+		Email    string `validate:"required_unless:Role guest"`
+		Ratio    float64
+		Limit    float64 `validate:"ltfield:Ratio"`
+	}
+
+	// Meeting validates itself by value receiver.
+	Meeting struct {
+		Title  string `validate:"required"`
+		Length time.Duration
+		Break  time.Duration
+	}
+
+	// Schedule validates itself by pointer receiver.
+	Schedule struct {
+		Meetings []Meeting `validate:"nested"`
+		Days     int
+		fails    bool
+	}
+
+	UnknownField struct {
+		EndsAt time.Time `validate:"gtfield:Start"`
+	}
+
+	DifferentTypes struct {
+		Min int64
+		Max int `validate:"gtfield:Min"`
+	}
+
+	UnorderedType struct {
+		A bool
+		B bool `validate:"gtfield:A"`
+	}
+
+	RequiredIfUnknownField struct {
+		Phone string `validate:"required_if:Role"`
+	}
+
+	InterfaceFields struct {
+		A interface{} `validate:"eqfield:B"`
+		B interface{}
+	}
+
+	StructWithInterface struct {
+		A struct{ V interface{} } `validate:"nefield:B"`
+		B struct{ V interface{} }
+	}
+
+	UnexportedField struct {
+		start  time.Time
+		EndsAt time.Time `validate:"gtfield:start"`
+	}
+
+	RequiredIfUnexportedField struct {
+		role  string
+		Phone string `validate:"required_if:role admin"`
+	}
+)
+
+func (m Meeting) Validate() error {
+	if m.Break > m.Length {
+		return errOverlap
+	}
+
+	return nil
+}
+
+func (s *Schedule) Validate() error {
+	if s.fails {
+		return ValidationErrors{
+			{Field: "Days", Rule: "weekdays", Err: fmt.Errorf("%d days in week", s.Days)},
+			{Field: "", Rule: "total", Err: errors.New("too many meetings")},
+		}
+	}
+
+	if s.Days > 7 {
+		return ValidationError{Field: "Days", Rule: "week", Arg: "7", Value: s.Days, Err: errors.New("too many days")}
+	}
+
+	return nil
+}
+
+func TestCrossFieldRules(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	valid := Event{
+		StartsAt: now,
+		EndsAt:   after,
+		BreakAt:  &now,
+		Min:      1,
+		Max:      1,
+		Password: "password",
+		Confirm:  "password",
+		Login:    "login",
+		Role:     "admin",
+		Phone:    "79001234567",
+		Email:    "admin@example.com",
+		Ratio:    0.5,
+		Limit:    0.25,
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		if err := Validate(valid); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		guest := valid
+		guest.BreakAt = nil
+		guest.Role, guest.Phone, guest.Email = "guest", "", ""
+
+		if err := Validate(guest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		event := valid
+		event.EndsAt = before
+		event.BreakAt = &before
+		event.Max = 0
+		event.Confirm = "pa55word"
+		event.Login = "password"
+		event.Phone = ""
+		event.Role = "stuff"
+		event.Email = ""
+		event.Limit = 0.5
+
+		requireValidationErrors(t, Validate(event), ValidationErrors{
+			{Field: "EndsAt", Err: ErrGtField},
+			{Field: "BreakAt", Err: ErrGteField},
+			{Field: "Max", Err: ErrGteField},
+			{Field: "Confirm", Err: ErrEqField},
+			{Field: "Login", Err: ErrNeField},
+			{Field: "Email", Err: ErrRequiredIf},
+			{Field: "Limit", Err: ErrLtField},
+		})
+
+		event.Role = "admin"
+
+		var errs ValidationErrors
+		if err := Validate(event); !errors.As(err, &errs) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		phone := errs[5]
+		if phone.Field != "Phone" || phone.Rule != ruleRequiredIf || phone.Arg != "Role admin" ||
+			!errors.Is(phone, ErrRequiredIf) || !errors.Is(phone, ErrRequired) || phone.Message() != "Phone is required for Role admin" {
+			t.Fatalf("unexpected error: %#v, message: %q", phone, phone.Message())
+		}
+	})
+
+	t.Run("ProgrammerErrors", func(t *testing.T) {
+		tests := []struct {
+			in          interface{}
+			expectedErr error
+		}{
+			{in: UnknownField{}, expectedErr: ErrInvalidRuleArg},
+			{in: DifferentTypes{}, expectedErr: ErrInvalidRuleArg},
+			{in: UnorderedType{}, expectedErr: ErrUnsupportedType},
+			{in: RequiredIfUnknownField{}, expectedErr: ErrInvalidRuleArg},
+			{in: InterfaceFields{A: []int{1}, B: []int{1}}, expectedErr: ErrUnsupportedType},
+			{in: StructWithInterface{}, expectedErr: ErrUnsupportedType},
+			{in: UnexportedField{start: time.Now()}, expectedErr: ErrInvalidRuleArg},
+			{in: RequiredIfUnexportedField{role: "admin"}, expectedErr: ErrInvalidRuleArg},
+		}
+
+		for _, tt := range tests {
+			if err := Validate(tt.in); !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("unexpected error for %T: %v, expected: %v", tt.in, err, tt.expectedErr)
+			}
+		}
+	})
+}
+
+func TestStructValidator(t *testing.T) {
+	t.Run("ValueReceiver", func(t *testing.T) {
+		err := Validate(Meeting{Length: time.Minute, Break: time.Hour})
+		requireValidationErrors(t, err, ValidationErrors{
+			{Field: "Title", Err: ErrRequired},
+			{Field: "", Err: errOverlap},
+		})
+
+		if expected := "Title: value is required; break overlaps event"; err.Error() != expected {
+			t.Fatalf("unexpected error text: %q, expected: %q", err.Error(), expected)
+		}
+	})
+
+	t.Run("PointerReceiver", func(t *testing.T) {
+		schedule := Schedule{
+			Meetings: []Meeting{{Title: "daily"}, {Title: "retro", Length: time.Minute, Break: time.Hour}},
+			Days:     8,
+		}
+
+		expected := ValidationErrors{
+			{Field: "Meetings[1]", Err: errOverlap},
+			{Field: "Days", Err: nil},
+		}
+
+		// Struct is validated by value and by pointer.
+		for _, in := range []interface{}{schedule, &schedule} {
+			var errs ValidationErrors
+			if err := Validate(in); !errors.As(err, &errs) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(errs) != len(expected) || errs[0].Field != expected[0].Field || !errors.Is(errs[0], errOverlap) {
+				t.Fatalf("unexpected errors: %v, expected: %v", errs, expected)
+			}
+
+			if days := errs[1]; days.Field != "Days" || days.Rule != "week" || days.Value != 8 {
+				t.Fatalf("unexpected error: %#v", days)
+			}
+		}
+	})
+
+	t.Run("ValidationErrors", func(t *testing.T) {
+		type Calendar struct {
+			Schedule Schedule `validate:"nested"`
+		}
+
+		var errs ValidationErrors
+		if err := Validate(Calendar{Schedule: Schedule{fails: true}}); !errors.As(err, &errs) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(errs) != 2 || errs[0].Field != "Schedule.Days" || errs[1].Field != "Schedule" {
+			t.Fatalf("unexpected errors: %#v", errs)
+		}
+
+		if msg := errs.Translate(Russian)[0].Message(); msg != "поле Schedule.Days заполнено неверно: 0 days in week" {
+			t.Fatalf("unexpected message: %q", msg)
+		}
+	})
+}
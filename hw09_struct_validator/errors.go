@@ -0,0 +1,134 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidationError is an error of a field value. Err wraps one of errors
+// of built-in rules, like ErrLen, or an error of a custom rule.
+// ValidationError isn't comparable, as Value may be a slice or a map,
+// use errors.Is to match it with another ValidationError.
+type ValidationError struct {
+	// Field is a path of the field, like "Address.Phones[2]".
+	Field string
+	// Rule and Arg are name and argument of the failed rule.
+	Rule string
+	Arg  string
+	// Value is the actual value of the field, or nil for nil pointers.
+	Value interface{}
+	Err   error
+	// translate translates the error for Message,
+	// it is set by the Validator returned the error.
+	translate func(ValidationError) string
+}
+
+// Error returns the field path and the error. Errors of the validated
+// struct itself, returned by its Validate method, have no field path.
+func (v ValidationError) Error() string {
+	if v.Field == "" {
+		return v.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %v", v.Field, v.Err)
+}
+
+func (v ValidationError) Unwrap() error {
+	return v.Err
+}
+
+// Is reports whether target is a ValidationError of the same field, rule
+// and argument, which Err matches Err of v. Values aren't compared.
+func (v ValidationError) Is(target error) bool {
+	t, ok := target.(ValidationError)
+
+	return ok && v.Field == t.Field && v.Rule == t.Rule && v.Arg == t.Arg && errors.Is(v.Err, t.Err)
+}
+
+// Message returns human readable message of the error translated
+// by translator of the Validator returned the error, English by default.
+func (v ValidationError) Message() string {
+	if v.translate == nil {
+		return English.Translate(v)
+	}
+
+	return v.translate(v)
+}
+
+// validationErrorJSON is JSON representation of ValidationError.
+type validationErrorJSON struct {
+	Field   string          `json:"field"`
+	Rule    string          `json:"rule"`
+	Arg     string          `json:"arg,omitempty"`
+	Value   json.RawMessage `json:"value"`
+	Message string          `json:"message"`
+}
+
+// MarshalJSON returns JSON object with field, rule, arg, value and message
+// of the error. Values which can't be marshalled are written as strings.
+func (v ValidationError) MarshalJSON() ([]byte, error) {
+	value, err := json.Marshal(v.Value)
+	if err != nil {
+		if value, err = json.Marshal(fmt.Sprint(v.Value)); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(validationErrorJSON{
+		Field:   v.Field,
+		Rule:    v.Rule,
+		Arg:     v.Arg,
+		Value:   value,
+		Message: v.Message(),
+	})
+}
+
+// ValidationErrors is a list of all validation errors of a struct.
+// It is marshalled into JSON as an array of ValidationError.
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, err := range v {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of errors matches target, so errors.Is
+// finds an error of a rule, like ErrLen, in the list.
+func (v ValidationErrors) Is(target error) bool {
+	for _, err := range v {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Translate returns copy of errors which messages are translated by t,
+// for example, according with language of a request.
+func (v ValidationErrors) Translate(t Translator) ValidationErrors {
+	translated := make(ValidationErrors, len(v))
+
+	for i, err := range v {
+		err.translate = t.Translate
+		translated[i] = err
+	}
+
+	return translated
+}
+
+// Messages returns messages of errors.
+func (v ValidationErrors) Messages() []string {
+	msgs := make([]string, 0, len(v))
+	for _, err := range v {
+		msgs = append(msgs, err.Message())
+	}
+
+	return msgs
+}
@@ -0,0 +1,210 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type (
+	Address struct {
+		City   string   `validate:"required"`
+		Phones []string `validate:"len:11"`
+	}
+
+	Customer struct {
+		Name    string  `validate:"minlen:2"`
+		Age     int     `validate:"min:18"`
+		Address Address `validate:"nested"`
+		Email   *string `validate:"required"`
+	}
+
+	Channel struct {
+		Events chan int `validate:"open"`
+	}
+
+	Team struct {
+		Members []string `validate:"minlen:2"`
+	}
+)
+
+var invalidCustomer = Customer{
+	Name:    "J",
+	Age:     17,
+	Address: Address{Phones: []string{"79001234567", "79001234567", "123"}},
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	var errs ValidationErrors
+	if err := Validate(invalidCustomer); !errors.As(err, &errs) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ValidationError{
+		{Field: "Name", Rule: "minlen", Arg: "2", Value: "J", Err: ErrMinLen},
+		{Field: "Age", Rule: "min", Arg: "18", Value: 17, Err: ErrMin},
+		{Field: "Address.City", Rule: "required", Value: "", Err: ErrRequired},
+		{Field: "Address.Phones[2]", Rule: "len", Arg: "11", Value: "123", Err: ErrLen},
+		{Field: "Email", Rule: "required", Value: nil, Err: ErrRequired},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors: %v, expected: %v", errs, expected)
+	}
+
+	for i, e := range expected {
+		err := errs[i]
+		if err.Field != e.Field || err.Rule != e.Rule || err.Arg != e.Arg ||
+			!reflect.DeepEqual(err.Value, e.Value) || !errors.Is(err, e.Err) {
+			t.Fatalf("unexpected error %d: %#v, expected: %#v", i, err, e)
+		}
+	}
+}
+
+func TestValidationErrorIs(t *testing.T) {
+	var errs ValidationErrors
+	if err := Validate(Team{Members: []string{"john"}}); !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Value of the error is a slice, which can't be compared by ==.
+	if !errors.Is(errs[0], errs[0]) {
+		t.Fatalf("error doesn't match itself: %v", errs[0])
+	}
+
+	if !errors.Is(errs[0], ValidationError{Field: "Members", Rule: "minlen", Arg: "2", Err: ErrMinLen}) {
+		t.Fatalf("error doesn't match ValidationError of the field: %v", errs[0])
+	}
+
+	if errors.Is(errs[0], ValidationError{Field: "Members", Rule: "minlen", Arg: "2", Err: ErrMaxLen}) {
+		t.Fatalf("error matches ValidationError with another Err: %v", errs[0])
+	}
+
+	if errors.Is(errs[0], ValidationError{Field: "Owner", Rule: "minlen", Arg: "2", Err: ErrMinLen}) {
+		t.Fatalf("error matches ValidationError of another field: %v", errs[0])
+	}
+}
+
+func TestValidationErrorsIs(t *testing.T) {
+	err := Validate(invalidCustomer)
+
+	for _, target := range []error{ErrMinLen, ErrMin, ErrRequired, ErrLen} {
+		if !errors.Is(err, target) {
+			t.Fatalf("error doesn't match %v: %v", target, err)
+		}
+	}
+
+	if !errors.Is(err, ValidationError{Field: "Address.Phones[2]", Rule: "len", Arg: "11", Err: ErrLen}) {
+		t.Fatalf("error doesn't match ValidationError of the field: %v", err)
+	}
+
+	if errors.Is(err, ErrMax) {
+		t.Fatalf("error matches %v: %v", ErrMax, err)
+	}
+}
+
+func TestValidationErrorsJSON(t *testing.T) {
+	err := Validate(invalidCustomer)
+
+	data, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatalf("unexpected error in Marshal: %v", jsonErr)
+	}
+
+	expected := `[` +
+		`{"field":"Name","rule":"minlen","arg":"2","value":"J","message":"Name length must be at least 2"},` +
+		`{"field":"Age","rule":"min","arg":"18","value":17,"message":"Age must be at least 18"},` +
+		`{"field":"Address.City","rule":"required","value":"","message":"Address.City is required"},` +
+		`{"field":"Address.Phones[2]","rule":"len","arg":"11","value":"123",` +
+		`"message":"Address.Phones[2] must be exactly 11 characters long"},` +
+		`{"field":"Email","rule":"required","value":null,"message":"Email is required"}` +
+		`]`
+
+	if string(data) != expected {
+		t.Fatalf("unexpected JSON:\n%s\nexpected:\n%s", data, expected)
+	}
+
+	t.Run("UnsupportedValue", func(t *testing.T) {
+		v := New()
+
+		if err := v.RegisterRule("open", func(reflect.Value, string) error { return errors.New("closed") }); err != nil {
+			t.Fatalf("unexpected error in RegisterRule: %v", err)
+		}
+
+		data, err := json.Marshal(v.Validate(Channel{}))
+		if err != nil {
+			t.Fatalf("unexpected error in Marshal: %v", err)
+		}
+
+		expected := `[{"field":"Events","rule":"open","value":"\u003cnil\u003e","message":"Events is invalid: closed"}]`
+		if string(data) != expected {
+			t.Fatalf("unexpected JSON: %s, expected: %s", data, expected)
+		}
+	})
+}
+
+func TestTranslators(t *testing.T) {
+	russian := []string{
+		"длина поля Name должна быть не меньше 2",
+		"поле Age должно быть не меньше 18",
+		"поле Address.City обязательно для заполнения",
+		"длина поля Address.Phones[2] должна быть равна 11",
+		"поле Email обязательно для заполнения",
+	}
+
+	t.Run("WithTranslator", func(t *testing.T) {
+		var errs ValidationErrors
+		if err := New(WithTranslator(Russian)).Validate(invalidCustomer); !errors.As(err, &errs) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(errs.Messages(), russian) {
+			t.Fatalf("unexpected messages: %q, expected: %q", errs.Messages(), russian)
+		}
+	})
+
+	t.Run("Translate", func(t *testing.T) {
+		var errs ValidationErrors
+		if err := Validate(invalidCustomer); !errors.As(err, &errs) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if msgs := errs.Translate(Russian).Messages(); !reflect.DeepEqual(msgs, russian) {
+			t.Fatalf("unexpected messages: %q, expected: %q", msgs, russian)
+		}
+
+		if msg := errs[0].Message(); msg != "Name length must be at least 2" {
+			t.Fatalf("original errors are translated: %q", msg)
+		}
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		codes := TranslatorFunc(func(e ValidationError) string { return e.Field + "." + e.Rule })
+
+		var errs ValidationErrors
+		if err := Validate(invalidCustomer); !errors.As(err, &errs) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if msg := errs.Translate(codes)[1].Message(); msg != "Age.min" {
+			t.Fatalf("unexpected message: %q, expected: %q", msg, "Age.min")
+		}
+	})
+
+	t.Run("AllRules", func(t *testing.T) {
+		for name := range builtinRules {
+			if name == ruleNested {
+				continue
+			}
+
+			if _, ok := English.Templates[name]; !ok {
+				t.Fatalf("missing English template of %q", name)
+			}
+
+			if _, ok := Russian.Templates[name]; !ok {
+				t.Fatalf("missing Russian template of %q", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// planResult is a cached result of building a plan,
+// programmer errors are cached too.
+type planResult struct {
+	plan *structPlan
+	err  error
+}
+
+// structPlan is a list of validated fields of a struct type.
+type structPlan struct {
+	fields []fieldPlan
+	hook   hookKind
+}
+
+// hookKind describes how a struct implements StructValidator.
+type hookKind int
+
+const (
+	noHook hookKind = iota
+	valueHook
+	// pointerHook means that StructValidator is implemented by pointer to struct.
+	pointerHook
+)
+
+var structValidatorType = reflect.TypeOf((*StructValidator)(nil)).Elem()
+
+// fieldPlan describes how to validate a struct field.
+type fieldPlan struct {
+	index int
+	name  string
+	// fieldChecks check the field itself, see fieldRules.
+	fieldChecks []fieldRuleCheck
+	// collection is true if elements of slice, array or map field
+	// (or pointer to it) are validated by checks instead of the field.
+	collection bool
+	// nested is true if the field (or its elements) is a struct
+	// or pointer to struct validated by its own plan.
+	nested bool
+	// checks check the field or its elements after dereferencing pointers,
+	// nil pointers aren't checked.
+	checks []ruleCheck
+}
+
+// ruleCheck is a compiled rule.
+type ruleCheck struct {
+	rule  rule
+	check valueCheck
+}
+
+// fieldRuleCheck is a compiled rule of fieldRules.
+type fieldRuleCheck struct {
+	rule  rule
+	check fieldCheck
+}
+
+// planFor returns cached validation plan of struct type t, building it if needed.
+func (v *Validator) planFor(t reflect.Type) (*structPlan, error) {
+	v.mu.RLock()
+	plans := v.plans
+	v.mu.RUnlock()
+
+	if cached, ok := plans.Load(t); ok {
+		result := cached.(planResult)
+		return result.plan, result.err
+	}
+
+	plan, err := v.buildPlan(t)
+
+	// Concurrent builders of the same type get equal results,
+	// so it doesn't matter which one is stored. If a rule is registered
+	// meanwhile, the plan is stored into the replaced cache and isn't used.
+	cached, _ := plans.LoadOrStore(t, planResult{plan: plan, err: err})
+	result := cached.(planResult)
+
+	return result.plan, result.err
+}
+
+// buildPlan parses validate tags of exported fields of struct type t
+// and compiles their rules. Plans of nested structs are built when they
+// are validated first time, so recursive types are supported.
+func (v *Validator) buildPlan(t reflect.Type) (*structPlan, error) {
+	plan := &structPlan{}
+
+	switch {
+	case t.Implements(structValidatorType):
+		plan.hook = valueHook
+	case reflect.PtrTo(t).Implements(structValidatorType):
+		plan.hook = pointerHook
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup(validateTag)
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+
+		fp, err := v.buildFieldPlan(t, field.Type, tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", t, field.Name, err)
+		}
+
+		fp.index = i
+		fp.name = field.Name
+		plan.fields = append(plan.fields, fp)
+	}
+
+	return plan, nil
+}
+
+// buildFieldPlan returns plan of field of type t with validate tag
+// in struct of type parent.
+func (v *Validator) buildFieldPlan(parent, t reflect.Type, tag string) (fieldPlan, error) {
+	rules, err := parseRules(tag)
+	if err != nil {
+		return fieldPlan{}, err
+	}
+
+	var (
+		fp        fieldPlan
+		elemRules []rule
+	)
+
+	for _, r := range rules {
+		if !fieldRules[r.name] {
+			elemRules = append(elemRules, r)
+			continue
+		}
+
+		check, err := compileFieldRule(r, parent, t)
+		if err != nil {
+			return fieldPlan{}, err
+		}
+
+		fp.fieldChecks = append(fp.fieldChecks, fieldRuleCheck{rule: r, check: check})
+	}
+
+	if len(elemRules) == 0 {
+		return fp, nil
+	}
+
+	t = derefType(t)
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Slice, reflect.Array, reflect.Map:
+		fp.collection = true
+		t = derefType(t.Elem())
+	}
+
+	if hasRule(elemRules, ruleNested) {
+		if len(elemRules) > 1 {
+			return fieldPlan{}, fmt.Errorf("%w: %q can be combined only with %s rules", ErrInvalidTag, ruleNested, fieldRulesList())
+		}
+
+		if t.Kind() != reflect.Struct {
+			return fieldPlan{}, fmt.Errorf("%w %s for %q", ErrUnsupportedType, t, ruleNested)
+		}
+
+		fp.nested = true
+
+		return fp, nil
+	}
+
+	for _, r := range elemRules {
+		check, err := v.compileRule(r, t)
+		if err != nil {
+			return fieldPlan{}, err
+		}
+
+		fp.checks = append(fp.checks, ruleCheck{rule: r, check: check})
+	}
+
+	return fp, nil
+}
+
+// derefType returns type pointed by pointer type t, or t itself.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// fieldRulesList returns comma-separated sorted names of fieldRules.
+func fieldRulesList() string {
+	names := make([]string, 0, len(fieldRules))
+	for name := range fieldRules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
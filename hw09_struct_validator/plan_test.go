@@ -0,0 +1,163 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Node struct {
+	Name string `validate:"len:1"`
+	Next *Node  `validate:"nested"`
+}
+
+func TestPlanFor(t *testing.T) {
+	t.Run("Cached", func(t *testing.T) {
+		first, err := defaultValidator.planFor(reflect.TypeOf(User{}))
+		if err != nil {
+			t.Fatalf("unexpected error in planFor: %v", err)
+		}
+
+		second, err := defaultValidator.planFor(reflect.TypeOf(User{}))
+		if err != nil {
+			t.Fatalf("unexpected error in planFor: %v", err)
+		}
+
+		if first != second {
+			t.Fatal("plan isn't cached")
+		}
+
+		if len(first.fields) != 5 {
+			t.Fatalf("unexpected count of validated fields: %d, expected: %d", len(first.fields), 5)
+		}
+	})
+
+	t.Run("CachedError", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := defaultValidator.planFor(reflect.TypeOf(UnknownRule{})); !errors.Is(err, ErrUnknownRule) {
+				t.Fatalf("unexpected error in planFor: %v, expected: %v", err, ErrUnknownRule)
+			}
+		}
+	})
+
+	t.Run("InvalidFieldOfEmptySlice", func(t *testing.T) {
+		type Flags struct {
+			Values []bool `validate:"min:0"`
+		}
+
+		if err := Validate(Flags{}); !errors.Is(err, ErrUnsupportedType) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrUnsupportedType)
+		}
+	})
+
+	t.Run("ErrorOfAnonymousStruct", func(t *testing.T) {
+		_, err := defaultValidator.planFor(reflect.TypeOf(struct {
+			Count int `validate:"minlen:1"`
+		}{}))
+
+		expected := "field struct {"
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("unexpected error in planFor: %v, expected prefix: %s", err, expected)
+		}
+	})
+}
+
+func TestValidateRecursiveType(t *testing.T) {
+	list := Node{Name: "a", Next: &Node{Name: "b", Next: &Node{Name: "long"}}}
+
+	requireValidationErrors(t, Validate(list), ValidationErrors{{Field: "Next.Next.Name", Err: ErrLen}})
+}
+
+func TestValidateConcurrent(t *testing.T) {
+	invalid := User{ID: "short", Age: 10, Email: validUser.Email, Role: "admin"}
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 100)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			if err := Validate(validUser); err != nil {
+				errs <- err
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			var validationErrs ValidationErrors
+			if err := Validate(invalid); !errors.As(err, &validationErrs) || len(validationErrs) != 2 {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	invalid := User{
+		ID:     "short",
+		Age:    17,
+		Email:  "john.example.com",
+		Role:   "guest",
+		Phones: []string{"79001234567", "123"},
+	}
+
+	b.Run("Valid", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if err := Validate(validUser); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Invalid", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if err := Validate(invalid); err == nil {
+				b.Fatal("expected validation errors")
+			}
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := Validate(validUser); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	})
+
+	// Uncached shows the cost of building a plan on every call,
+	// which is what caching saves.
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			defaultValidator.plans.Delete(reflect.TypeOf(validUser))
+
+			if err := Validate(validUser); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
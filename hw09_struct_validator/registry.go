@@ -0,0 +1,114 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidRuleName = errors.New("invalid rule name")
+	ErrRuleExists      = errors.New("rule already exists")
+)
+
+// RuleFunc checks value v of a field (or of an element of slice field)
+// by a custom rule with argument arg, which is an empty string for rules
+// without argument. It returns error if v doesn't satisfy the rule,
+// it is reported in ValidationErrors. Errors wrapping ErrInvalidRuleArg or
+// ErrUnsupportedType mean that the rule is used incorrectly, Validate
+// returns them as is.
+type RuleFunc func(v reflect.Value, arg string) error
+
+// Validator validates structs by built-in rules and custom rules registered
+// in it, so rules of different validators don't collide. It is safe
+// for concurrent use. Its zero value isn't usable, use New.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFunc
+	// plans caches validation plans by struct types, so tags are parsed
+	// and regexps are compiled once per type.
+	// It is replaced on registration of a rule.
+	plans *sync.Map // map[reflect.Type]planResult
+	// translator translates messages of returned errors.
+	translator Translator
+}
+
+// Option configures a Validator.
+type Option func(*Validator)
+
+// WithTranslator sets translator of messages of errors returned by the Validator.
+// English is used by default.
+func WithTranslator(t Translator) Option {
+	return func(v *Validator) {
+		v.translator = t
+	}
+}
+
+// defaultValidator is used by package level Validate and RegisterRule.
+var defaultValidator = New()
+
+// New returns a Validator with built-in rules only.
+func New(opts ...Option) *Validator {
+	v := &Validator{
+		rules:      make(map[string]RuleFunc),
+		plans:      &sync.Map{},
+		translator: English,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// RegisterRule registers custom rule in the default Validator.
+// See Validator.RegisterRule for details.
+func RegisterRule(name string, fn RuleFunc) error {
+	return defaultValidator.RegisterRule(name, fn)
+}
+
+// RegisterRule registers custom rule name checked by fn, so it can be used
+// in validate tags, like "name" or "name:arg", alone or combined with other
+// rules. It returns ErrInvalidRuleName if name contains rule separators
+// and ErrRuleExists if the rule is built-in or already registered.
+func (v *Validator) RegisterRule(name string, fn RuleFunc) error {
+	if name == "" || strings.ContainsAny(name, rulesSeparator+argSeparator) || fn == nil {
+		return fmt.Errorf("%w %q", ErrInvalidRuleName, name)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, ok := v.rules[name]; ok || builtinRules[name] {
+		return fmt.Errorf("%w %q", ErrRuleExists, name)
+	}
+
+	v.rules[name] = fn
+
+	// Cached plans may contain ErrUnknownRule for the new rule.
+	v.plans = &sync.Map{}
+
+	return nil
+}
+
+// compileRule returns check of values of type t by built-in or custom rule r.
+func (v *Validator) compileRule(r rule, t reflect.Type) (valueCheck, error) {
+	if builtinRules[r.name] {
+		return compileBuiltinRule(r, t)
+	}
+
+	v.mu.RLock()
+	fn, ok := v.rules[r.name]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownRule, r.name)
+	}
+
+	arg := r.arg
+
+	return func(value reflect.Value) error { return fn(value, arg) }, nil
+}
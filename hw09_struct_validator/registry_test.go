@@ -0,0 +1,192 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	errNotUUID      = errors.New("not a uuid")
+	errNotFuture    = errors.New("not in the future")
+	errNotDivisible = errors.New("not divisible")
+
+	uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+func uuidRule(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("%w %s for uuid", ErrUnsupportedType, v.Type())
+	}
+
+	if !uuidRegexp.MatchString(v.String()) {
+		return errNotUUID
+	}
+
+	return nil
+}
+
+func futureRule(v reflect.Value, _ string) error {
+	t, ok := v.Interface().(time.Time)
+	if !ok {
+		return fmt.Errorf("%w %s for future", ErrUnsupportedType, v.Type())
+	}
+
+	if !t.After(time.Now()) {
+		return errNotFuture
+	}
+
+	return nil
+}
+
+func divisibleRule(v reflect.Value, arg string) error {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || n == 0 {
+		return fmt.Errorf("%w of divisible: %q", ErrInvalidRuleArg, arg)
+	}
+
+	if v.Int()%n != 0 {
+		return fmt.Errorf("%w by %d", errNotDivisible, n)
+	}
+
+	return nil
+}
+
+type (
+	Order struct {
+		ID        string    `validate:"len:36|uuid"`
+		Items     []string  `validate:"uuid"`
+		Count     int       `validate:"min:1|divisible:3"`
+		DeliverAt time.Time `validate:"future"`
+	}
+
+	InvalidDivisible struct {
+		Count int `validate:"divisible:zero"`
+	}
+
+	UUIDForInt struct {
+		Count int `validate:"uuid"`
+	}
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	t.Helper()
+
+	v := New()
+
+	for name, fn := range map[string]RuleFunc{"uuid": uuidRule, "future": futureRule, "divisible": divisibleRule} {
+		if err := v.RegisterRule(name, fn); err != nil {
+			t.Fatalf("unexpected error in RegisterRule: %v", err)
+		}
+	}
+
+	return v
+}
+
+func TestValidatorCustomRules(t *testing.T) {
+	v := newTestValidator(t)
+
+	t.Run("Valid", func(t *testing.T) {
+		order := Order{
+			ID:        validUser.ID,
+			Items:     []string{validUser.ID},
+			Count:     6,
+			DeliverAt: time.Now().Add(time.Hour),
+		}
+
+		if err := v.Validate(order); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		order := Order{
+			ID:        "not-uuid",
+			Items:     []string{validUser.ID, "bad"},
+			Count:     4,
+			DeliverAt: time.Now().Add(-time.Hour),
+		}
+
+		requireValidationErrors(t, v.Validate(order), ValidationErrors{
+			{Field: "ID", Err: ErrLen},
+			{Field: "ID", Err: errNotUUID},
+			{Field: "Items[1]", Err: errNotUUID},
+			{Field: "Count", Err: errNotDivisible},
+			{Field: "DeliverAt", Err: errNotFuture},
+		})
+	})
+
+	t.Run("ProgrammerErrors", func(t *testing.T) {
+		if err := v.Validate(InvalidDivisible{Count: 1}); !errors.Is(err, ErrInvalidRuleArg) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrInvalidRuleArg)
+		}
+
+		if err := v.Validate(UUIDForInt{}); !errors.Is(err, ErrUnsupportedType) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrUnsupportedType)
+		}
+	})
+
+	t.Run("OtherValidator", func(t *testing.T) {
+		if err := New().Validate(Order{}); !errors.Is(err, ErrUnknownRule) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrUnknownRule)
+		}
+	})
+}
+
+func TestRegisterRule(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "a|b", "a:b"} {
+			if err := New().RegisterRule(name, uuidRule); !errors.Is(err, ErrInvalidRuleName) {
+				t.Fatalf("unexpected error for %q: %v, expected: %v", name, err, ErrInvalidRuleName)
+			}
+		}
+
+		if err := New().RegisterRule("uuid", nil); !errors.Is(err, ErrInvalidRuleName) {
+			t.Fatalf("unexpected error for nil func: %v, expected: %v", err, ErrInvalidRuleName)
+		}
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		v := newTestValidator(t)
+
+		for _, name := range []string{"len", "nested", "uuid"} {
+			if err := v.RegisterRule(name, uuidRule); !errors.Is(err, ErrRuleExists) {
+				t.Fatalf("unexpected error for %q: %v, expected: %v", name, err, ErrRuleExists)
+			}
+		}
+	})
+
+	t.Run("AfterValidation", func(t *testing.T) {
+		type Ticket struct {
+			Code string `validate:"ticket"`
+		}
+
+		v := New()
+
+		if err := v.Validate(Ticket{}); !errors.Is(err, ErrUnknownRule) {
+			t.Fatalf("unexpected error: %v, expected: %v", err, ErrUnknownRule)
+		}
+
+		if err := v.RegisterRule("ticket", uuidRule); err != nil {
+			t.Fatalf("unexpected error in RegisterRule: %v", err)
+		}
+
+		requireValidationErrors(t, v.Validate(Ticket{}), ValidationErrors{{Field: "Code", Err: errNotUUID}})
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		type Event struct {
+			StartsAt time.Time `validate:"test_default_future"`
+		}
+
+		if err := RegisterRule("test_default_future", futureRule); err != nil {
+			t.Fatalf("unexpected error in RegisterRule: %v", err)
+		}
+
+		requireValidationErrors(t, Validate(Event{}), ValidationErrors{{Field: "StartsAt", Err: errNotFuture}})
+	})
+}
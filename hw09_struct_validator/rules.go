@@ -0,0 +1,238 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors of values, they are wrapped into ValidationError.
+var (
+	ErrLen      = errors.New("invalid length")
+	ErrRegexp   = errors.New("doesn't match regexp")
+	ErrIn       = errors.New("isn't in allowed set")
+	ErrMin      = errors.New("less than minimum")
+	ErrMax      = errors.New("greater than maximum")
+	ErrRequired = errors.New("value is required")
+	ErrEmpty    = errors.New("value is empty")
+	ErrMinLen   = errors.New("length is less than minimum")
+	ErrMaxLen   = errors.New("length is greater than maximum")
+	ErrPrefix   = errors.New("doesn't have prefix")
+	ErrContains = errors.New("doesn't contain substring")
+	ErrGtField  = errors.New("isn't greater than field")
+	ErrGteField = errors.New("is less than field")
+	ErrLtField  = errors.New("isn't less than field")
+	ErrLteField = errors.New("is greater than field")
+	ErrEqField  = errors.New("isn't equal to field")
+	ErrNeField  = errors.New("is equal to field")
+
+	// ErrOneOf and ErrRequiredIf wrap ErrIn and ErrRequired,
+	// so errors.Is matches them with both errors.
+	ErrOneOf      = fmt.Errorf("%w of words", ErrIn)
+	ErrRequiredIf = fmt.Errorf("%w by another field", ErrRequired)
+)
+
+const (
+	ruleLen      = "len"
+	ruleRegexp   = "regexp"
+	ruleIn       = "in"
+	ruleMin      = "min"
+	ruleMax      = "max"
+	ruleNested   = "nested"
+	ruleRequired = "required"
+	ruleNotEmpty = "notempty"
+	ruleMinLen   = "minlen"
+	ruleMaxLen   = "maxlen"
+	rulePrefix   = "prefix"
+	ruleContains = "contains"
+	ruleOneOf    = "oneof"
+
+	ruleGtField        = "gtfield"
+	ruleGteField       = "gtefield"
+	ruleLtField        = "ltfield"
+	ruleLteField       = "ltefield"
+	ruleEqField        = "eqfield"
+	ruleNeField        = "nefield"
+	ruleRequiredIf     = "required_if"
+	ruleRequiredUnless = "required_unless"
+
+	// ruleStruct is a name of rule of errors returned by StructValidator.
+	ruleStruct = "struct"
+)
+
+const (
+	// rulesSeparator separates rules combined by logical AND.
+	rulesSeparator = "|"
+	// argSeparator separates rule name and its argument.
+	argSeparator = ":"
+	// listSeparator separates items of "in" rule argument.
+	listSeparator = ","
+	// oneOfSeparator separates items of "oneof" rule argument.
+	oneOfSeparator = " "
+)
+
+// builtinRules are names of built-in rules, they can't be registered.
+var builtinRules = map[string]bool{
+	ruleLen:      true,
+	ruleRegexp:   true,
+	ruleIn:       true,
+	ruleMin:      true,
+	ruleMax:      true,
+	ruleNested:   true,
+	ruleRequired: true,
+	ruleNotEmpty: true,
+	ruleMinLen:   true,
+	ruleMaxLen:   true,
+	rulePrefix:   true,
+	ruleContains: true,
+	ruleOneOf:    true,
+
+	ruleGtField:        true,
+	ruleGteField:       true,
+	ruleLtField:        true,
+	ruleLteField:       true,
+	ruleEqField:        true,
+	ruleNeField:        true,
+	ruleRequiredIf:     true,
+	ruleRequiredUnless: true,
+	ruleStruct:         true,
+}
+
+// fieldRules are rules which check the field itself. Other rules check
+// each element of slices, arrays and maps.
+var fieldRules = map[string]bool{
+	ruleRequired: true,
+	ruleNotEmpty: true,
+	ruleMinLen:   true,
+	ruleMaxLen:   true,
+
+	ruleGtField:        true,
+	ruleGteField:       true,
+	ruleLtField:        true,
+	ruleLteField:       true,
+	ruleEqField:        true,
+	ruleNeField:        true,
+	ruleRequiredIf:     true,
+	ruleRequiredUnless: true,
+}
+
+// crossRules are fieldRules which depend on other fields of the struct,
+// their arguments are names of the fields.
+var crossRules = map[string]bool{
+	ruleGtField:        true,
+	ruleGteField:       true,
+	ruleLtField:        true,
+	ruleLteField:       true,
+	ruleEqField:        true,
+	ruleNeField:        true,
+	ruleRequiredIf:     true,
+	ruleRequiredUnless: true,
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// rule is a parsed validation rule, like "min:10".
+type rule struct {
+	name string
+	arg  string
+}
+
+type (
+	// valueCheck returns error wrapping one of rule errors, like ErrLen,
+	// if value doesn't satisfy the rule.
+	valueCheck  func(reflect.Value) error
+	stringCheck func(string) error
+)
+
+func invalid(err error, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{err}, args...)...)
+}
+
+// parseRules parses rules of validate tag.
+func parseRules(tag string) ([]rule, error) {
+	parts := strings.Split(tag, rulesSeparator)
+	rules := make([]rule, 0, len(parts))
+
+	for _, part := range parts {
+		name, arg := part, ""
+		if i := strings.Index(part, argSeparator); i >= 0 {
+			name, arg = part[:i], part[i+len(argSeparator):]
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("%w %q: empty rule", ErrInvalidTag, tag)
+		}
+
+		rules = append(rules, rule{name: name, arg: arg})
+	}
+
+	return rules, nil
+}
+
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compileBuiltinRule returns check of values of type t
+// by built-in rule r, which isn't one of fieldRules.
+func compileBuiltinRule(r rule, t reflect.Type) (valueCheck, error) {
+	if t == timeType {
+		return compileTimeRule(r)
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String:
+		check, err := compileStringRule(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(v reflect.Value) error { return check(v.String()) }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return compileNumberRule(r, t)
+	default:
+		return nil, fmt.Errorf("%w %s for %q", ErrUnsupportedType, t, r.name)
+	}
+}
+
+// parseIntArg returns integer argument of rule r.
+func parseIntArg(r rule) (int64, error) {
+	n, err := strconv.ParseInt(r.arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w of %q: %v", ErrInvalidRuleArg, r.name, err)
+	}
+
+	return n, nil
+}
+
+// listArg returns items of "in" or "oneof" rule argument.
+func listArg(r rule) []string {
+	if r.name == ruleOneOf {
+		return strings.Fields(r.arg)
+	}
+
+	return strings.Split(r.arg, listSeparator)
+}
+
+// listErr returns error of value which isn't in "in" or "oneof" rule argument.
+func listErr(r rule) error {
+	if r.name == ruleOneOf {
+		return ErrOneOf
+	}
+
+	return ErrIn
+}
@@ -0,0 +1,164 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// compileCrossRule returns check of field of type t in struct of type parent
+// by one of crossRules, which compare the field with another field.
+// Fields are compared only if both of them are not nil pointers.
+func compileCrossRule(r rule, parent, t reflect.Type) (fieldCheck, error) {
+	if r.name == ruleRequiredIf || r.name == ruleRequiredUnless {
+		return compileRequiredIf(r, parent)
+	}
+
+	other, err := otherField(r, parent, r.arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if derefType(other.Type) != derefType(t) {
+		return nil, fmt.Errorf("%w of %q: field %s is %s, expected %s", ErrInvalidRuleArg, r.name, r.arg, other.Type, t)
+	}
+
+	compare, err := compareFunc(r, derefType(t))
+	if err != nil {
+		return nil, err
+	}
+
+	index := other.Index[0]
+	rel := relations[r.name]
+
+	return func(value, s reflect.Value) error {
+		a, b := indirect(value), indirect(s.Field(index))
+		if !a.IsValid() || !b.IsValid() {
+			return nil
+		}
+
+		if !rel.accept(compare(a, b)) {
+			return invalid(rel.err, "%v, expected %s %s %v", a.Interface(), rel.text, r.arg, b.Interface())
+		}
+
+		return nil
+	}, nil
+}
+
+// otherField returns field name of struct type parent compared by rule r.
+// Unexported fields are rejected, as their values can't be used.
+func otherField(r rule, parent reflect.Type, name string) (reflect.StructField, error) {
+	other, ok := parent.FieldByName(name)
+	if !ok || len(other.Index) != 1 {
+		return reflect.StructField{}, fmt.Errorf("%w of %q: no field %q in %s", ErrInvalidRuleArg, r.name, name, parent)
+	}
+
+	if other.PkgPath != "" {
+		return reflect.StructField{}, fmt.Errorf("%w of %q: field %q of %s is unexported", ErrInvalidRuleArg, r.name, name, parent)
+	}
+
+	return other, nil
+}
+
+// relation is a relation between fields required by a cross-field rule.
+type relation struct {
+	// accept reports whether result of fields comparison satisfies the relation.
+	accept func(int) bool
+	err    error
+	text   string
+}
+
+var relations = map[string]relation{
+	ruleGtField:  {accept: func(c int) bool { return c > 0 }, err: ErrGtField, text: "greater than"},
+	ruleGteField: {accept: func(c int) bool { return c >= 0 }, err: ErrGteField, text: "greater than or equal to"},
+	ruleLtField:  {accept: func(c int) bool { return c < 0 }, err: ErrLtField, text: "less than"},
+	ruleLteField: {accept: func(c int) bool { return c <= 0 }, err: ErrLteField, text: "less than or equal to"},
+	ruleEqField:  {accept: func(c int) bool { return c == 0 }, err: ErrEqField, text: "equal to"},
+	ruleNeField:  {accept: func(c int) bool { return c != 0 }, err: ErrNeField, text: "not equal to"},
+}
+
+// compareFunc returns function which compares values of type t and returns
+// -1, 0 or 1 like number.cmp. Only equality of unordered comparable types
+// is supported, they are considered greater if they aren't equal. Types
+// containing interfaces aren't supported, as their dynamic values may be
+// uncomparable.
+func compareFunc(r rule, t reflect.Type) (func(a, b reflect.Value) int, error) {
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		kind := numberKindOf(t)
+
+		return func(a, b reflect.Value) int { return numberOf(kind, a).cmp(numberOf(kind, b)) }, nil
+	}
+
+	if t == timeType {
+		return func(a, b reflect.Value) int { return compareTimes(timeOf(a), timeOf(b)) }, nil
+	}
+
+	if (r.name == ruleEqField || r.name == ruleNeField) && safelyComparable(t) {
+		return func(a, b reflect.Value) int {
+			if a.Interface() == b.Interface() {
+				return 0
+			}
+
+			return 1
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w %s for %q", ErrUnsupportedType, t, r.name)
+}
+
+// safelyComparable reports whether values of type t can be compared
+// by == without panic.
+func safelyComparable(t reflect.Type) bool {
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return safelyComparable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !safelyComparable(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+
+	return t.Comparable()
+}
+
+// compileRequiredIf returns check of field which is required if
+// (or unless) another field has a value. Argument of the rule is
+// a name of the field and its value separated by space, like "Role admin".
+func compileRequiredIf(r rule, parent reflect.Type) (fieldCheck, error) {
+	name, expected := r.arg, ""
+	if i := strings.Index(r.arg, oneOfSeparator); i >= 0 {
+		name, expected = r.arg[:i], r.arg[i+len(oneOfSeparator):]
+	}
+
+	other, err := otherField(r, parent, name)
+	if err != nil {
+		return nil, err
+	}
+
+	index := other.Index[0]
+	unless := r.name == ruleRequiredUnless
+
+	return func(value, s reflect.Value) error {
+		b := indirect(s.Field(index))
+		matches := b.IsValid() && fmt.Sprint(b.Interface()) == expected
+
+		if matches == unless || !value.IsZero() {
+			return nil
+		}
+
+		if unless {
+			return invalid(ErrRequiredIf, "%s isn't %s", name, expected)
+		}
+
+		return invalid(ErrRequiredIf, "%s is %s", name, expected)
+	}, nil
+}
@@ -0,0 +1,116 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// fieldCheck returns error wrapping one of rule errors if value
+// of a field of struct parent doesn't satisfy the rule.
+type fieldCheck func(value, parent reflect.Value) error
+
+// compileFieldRule returns check of field of type t in struct of type parent
+// by one of fieldRules.
+func compileFieldRule(r rule, parent, t reflect.Type) (fieldCheck, error) {
+	if crossRules[r.name] {
+		return compileCrossRule(r, parent, t)
+	}
+
+	check, err := compileValueRule(r, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value, _ reflect.Value) error { return check(value) }, nil
+}
+
+// compileValueRule returns check of field of type t by one of fieldRules,
+// which doesn't depend on other fields. Nil pointers are considered
+// zero values of their types.
+func compileValueRule(r rule, t reflect.Type) (valueCheck, error) {
+	if r.name == ruleRequired {
+		return func(v reflect.Value) error {
+			if v.IsZero() {
+				return ErrRequired
+			}
+
+			return nil
+		}, nil
+	}
+
+	elem := t
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	switch elem.Kind() { //nolint:exhaustive
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil, fmt.Errorf("%w %s for %q", ErrUnsupportedType, t, r.name)
+	}
+
+	if r.name == ruleNotEmpty {
+		return func(v reflect.Value) error {
+			v = indirect(v)
+
+			if !v.IsValid() || (v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "") || v.Len() == 0 {
+				return ErrEmpty
+			}
+
+			return nil
+		}, nil
+	}
+
+	limit, err := parseIntArg(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.name == ruleMinLen {
+		return func(v reflect.Value) error {
+			if l := lengthOf(v); l < limit {
+				return invalid(ErrMinLen, "%d, expected at least %d", l, limit)
+			}
+
+			return nil
+		}, nil
+	}
+
+	return func(v reflect.Value) error {
+		if l := lengthOf(v); l > limit {
+			return invalid(ErrMaxLen, "%d, expected at most %d", l, limit)
+		}
+
+		return nil
+	}, nil
+}
+
+// lengthOf returns count of characters of string v or count of elements
+// of slice, array or map v, dereferencing pointers.
+func lengthOf(v reflect.Value) int64 {
+	v = indirect(v)
+
+	switch {
+	case !v.IsValid():
+		return 0
+	case v.Kind() == reflect.String:
+		return int64(utf8.RuneCountInString(v.String()))
+	default:
+		return int64(v.Len())
+	}
+}
+
+// indirect dereferences pointers v. It returns invalid value for nil pointers.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
@@ -0,0 +1,181 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberKind is a kind of representation of numeric values.
+type numberKind int
+
+const (
+	intNumber numberKind = iota
+	uintNumber
+	floatNumber
+	// durationNumber is time.Duration, its rule arguments are durations, like "1m30s".
+	durationNumber
+)
+
+// number is a numeric value or rule argument of any kind,
+// so rules are implemented once for all numeric types.
+type number struct {
+	kind numberKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// numberKindOf returns kind of numbers of type t.
+func numberKindOf(t reflect.Type) numberKind {
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintNumber
+	case reflect.Float32, reflect.Float64:
+		return floatNumber
+	}
+
+	if t == durationType {
+		return durationNumber
+	}
+
+	return intNumber
+}
+
+// numberOf returns number of value v of kind.
+func numberOf(kind numberKind, v reflect.Value) number {
+	switch kind {
+	case uintNumber:
+		return number{kind: kind, u: v.Uint()}
+	case floatNumber:
+		return number{kind: kind, f: v.Float()}
+	case intNumber, durationNumber:
+	}
+
+	return number{kind: kind, i: v.Int()}
+}
+
+var errNaN = errors.New("NaN is not comparable")
+
+// parseNumber parses rule argument s as a number of type t,
+// so values which overflow t are rejected.
+func parseNumber(t reflect.Type, s string) (number, error) {
+	n := number{kind: numberKindOf(t)}
+
+	var err error
+
+	switch n.kind {
+	case intNumber:
+		n.i, err = strconv.ParseInt(s, 10, t.Bits())
+	case uintNumber:
+		n.u, err = strconv.ParseUint(s, 10, t.Bits())
+	case floatNumber:
+		n.f, err = strconv.ParseFloat(s, t.Bits())
+		if err == nil && math.IsNaN(n.f) {
+			err = errNaN
+		}
+	case durationNumber:
+		var d time.Duration
+		d, err = time.ParseDuration(s)
+		n.i = int64(d)
+	}
+
+	return n, err
+}
+
+// isNaN reports whether n is a float NaN, which is neither less
+// nor greater than any limit.
+func (n number) isNaN() bool {
+	return n.kind == floatNumber && math.IsNaN(n.f)
+}
+
+// cmp returns -1, 0 or 1 if n is less than, equal to or greater than m
+// of the same kind.
+func (n number) cmp(m number) int {
+	switch {
+	case n.kind == uintNumber && n.u < m.u,
+		n.kind == floatNumber && n.f < m.f,
+		(n.kind == intNumber || n.kind == durationNumber) && n.i < m.i:
+		return -1
+	case n.u == m.u && n.f == m.f && n.i == m.i:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func (n number) String() string {
+	switch n.kind {
+	case uintNumber:
+		return strconv.FormatUint(n.u, 10)
+	case floatNumber:
+		return strconv.FormatFloat(n.f, 'g', -1, 64)
+	case durationNumber:
+		return time.Duration(n.i).String()
+	case intNumber:
+	}
+
+	return strconv.FormatInt(n.i, 10)
+}
+
+// compileNumberRule returns check of numbers of type t by rule r.
+// NaN values fail both min and max rules.
+func compileNumberRule(r rule, t reflect.Type) (valueCheck, error) {
+	kind := numberKindOf(t)
+
+	switch r.name {
+	case ruleMin, ruleMax:
+		limit, err := parseNumber(t, r.arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w of %q: %v", ErrInvalidRuleArg, r.name, err)
+		}
+
+		if r.name == ruleMin {
+			return func(v reflect.Value) error {
+				if n := numberOf(kind, v); n.isNaN() || n.cmp(limit) < 0 {
+					return invalid(ErrMin, "%s, expected at least %s", n, limit)
+				}
+
+				return nil
+			}, nil
+		}
+
+		return func(v reflect.Value) error {
+			if n := numberOf(kind, v); n.isNaN() || n.cmp(limit) > 0 {
+				return invalid(ErrMax, "%s, expected at most %s", n, limit)
+			}
+
+			return nil
+		}, nil
+	case ruleIn, ruleOneOf:
+		items, errIn := listArg(r), listErr(r)
+		set := make([]number, 0, len(items))
+
+		for _, item := range items {
+			n, err := parseNumber(t, strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("%w of %q: %v", ErrInvalidRuleArg, r.name, err)
+			}
+
+			set = append(set, n)
+		}
+
+		return func(v reflect.Value) error {
+			n := numberOf(kind, v)
+
+			for _, item := range set {
+				if n.cmp(item) == 0 {
+					return nil
+				}
+			}
+
+			return invalid(errIn, "%s, expected one of %s", n, r.arg)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w %q for numbers", ErrUnknownRule, r.name)
+	}
+}
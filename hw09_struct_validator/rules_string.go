@@ -0,0 +1,70 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// compileStringRule returns check of strings by rule r.
+func compileStringRule(r rule) (stringCheck, error) {
+	switch r.name {
+	case ruleLen:
+		n, err := parseIntArg(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(s string) error {
+			if l := utf8.RuneCountInString(s); int64(l) != n {
+				return invalid(ErrLen, "%d, expected %d", l, n)
+			}
+
+			return nil
+		}, nil
+	case ruleRegexp:
+		re, err := regexp.Compile(r.arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w of %q: %v", ErrInvalidRuleArg, r.name, err)
+		}
+
+		return func(s string) error {
+			if !re.MatchString(s) {
+				return invalid(ErrRegexp, "%q doesn't match %s", s, re)
+			}
+
+			return nil
+		}, nil
+	case ruleIn, ruleOneOf:
+		set, errIn := listArg(r), listErr(r)
+
+		return func(s string) error {
+			for _, item := range set {
+				if s == item {
+					return nil
+				}
+			}
+
+			return invalid(errIn, "%q, expected one of %s", s, r.arg)
+		}, nil
+	case rulePrefix:
+		return func(s string) error {
+			if !strings.HasPrefix(s, r.arg) {
+				return invalid(ErrPrefix, "%q, expected prefix %q", s, r.arg)
+			}
+
+			return nil
+		}, nil
+	case ruleContains:
+		return func(s string) error {
+			if !strings.Contains(s, r.arg) {
+				return invalid(ErrContains, "%q, expected substring %q", s, r.arg)
+			}
+
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w %q for strings", ErrUnknownRule, r.name)
+	}
+}
@@ -0,0 +1,56 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// compileTimeRule returns check of time.Time values by rule r.
+// Arguments of min and max are times in RFC 3339 format,
+// like "2006-01-02T15:04:05Z".
+func compileTimeRule(r rule) (valueCheck, error) {
+	if r.name != ruleMin && r.name != ruleMax {
+		return nil, fmt.Errorf("%w %q for times", ErrUnknownRule, r.name)
+	}
+
+	limit, err := time.Parse(time.RFC3339, r.arg)
+	if err != nil {
+		return nil, fmt.Errorf("%w of %q: %v", ErrInvalidRuleArg, r.name, err)
+	}
+
+	if r.name == ruleMin {
+		return func(v reflect.Value) error {
+			if t := timeOf(v); t.Before(limit) {
+				return invalid(ErrMin, "%s, expected at least %s", t.Format(time.RFC3339), r.arg)
+			}
+
+			return nil
+		}, nil
+	}
+
+	return func(v reflect.Value) error {
+		if t := timeOf(v); t.After(limit) {
+			return invalid(ErrMax, "%s, expected at most %s", t.Format(time.RFC3339), r.arg)
+		}
+
+		return nil
+	}, nil
+}
+
+// timeOf returns time of value v of timeType.
+func timeOf(v reflect.Value) time.Time {
+	return v.Interface().(time.Time)
+}
+
+// compareTimes returns -1, 0 or 1 like number.cmp.
+func compareTimes(x, y time.Time) int {
+	switch {
+	case x.Before(y):
+		return -1
+	case x.After(y):
+		return 1
+	default:
+		return 0
+	}
+}
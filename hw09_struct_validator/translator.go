@@ -0,0 +1,91 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import "fmt"
+
+// Translator returns human readable message of validation error e.
+type Translator interface {
+	Translate(e ValidationError) string
+}
+
+// TranslatorFunc is a function which implements Translator.
+type TranslatorFunc func(e ValidationError) string
+
+func (f TranslatorFunc) Translate(e ValidationError) string {
+	return f(e)
+}
+
+// Messages translates errors by fmt templates of rules. Templates get
+// field path, rule argument, actual value and error as indexed
+// arguments %[1]s, %[2]s, %[3]v and %[4]v. Errors of rules without
+// template, like custom ones, are translated by Fallback.
+type Messages struct {
+	Templates map[string]string
+	Fallback  string
+}
+
+func (m Messages) Translate(e ValidationError) string {
+	template, ok := m.Templates[e.Rule]
+	if !ok {
+		template = m.Fallback
+	}
+
+	return fmt.Sprintf(template, e.Field, e.Arg, e.Value, e.Err)
+}
+
+// English translates errors of built-in rules into English.
+var English = Messages{
+	Templates: map[string]string{
+		ruleLen:      "%[1]s must be exactly %[2]s characters long",
+		ruleRegexp:   "%[1]s must match regular expression %[2]s",
+		ruleIn:       "%[1]s must be one of %[2]s",
+		ruleOneOf:    "%[1]s must be one of %[2]s",
+		ruleMin:      "%[1]s must be at least %[2]s",
+		ruleMax:      "%[1]s must be at most %[2]s",
+		ruleRequired: "%[1]s is required",
+		ruleNotEmpty: "%[1]s must not be empty",
+		ruleMinLen:   "%[1]s length must be at least %[2]s",
+		ruleMaxLen:   "%[1]s length must be at most %[2]s",
+		rulePrefix:   "%[1]s must start with %[2]q",
+		ruleContains: "%[1]s must contain %[2]q",
+
+		ruleGtField:        "%[1]s must be greater than %[2]s",
+		ruleGteField:       "%[1]s must be greater than or equal to %[2]s",
+		ruleLtField:        "%[1]s must be less than %[2]s",
+		ruleLteField:       "%[1]s must be less than or equal to %[2]s",
+		ruleEqField:        "%[1]s must be equal to %[2]s",
+		ruleNeField:        "%[1]s must not be equal to %[2]s",
+		ruleRequiredIf:     "%[1]s is required for %[2]s",
+		ruleRequiredUnless: "%[1]s is required unless %[2]s",
+		ruleStruct:         "%[4]v",
+	},
+	Fallback: "%[1]s is invalid: %[4]v",
+}
+
+// Russian translates errors of built-in rules into Russian.
+var Russian = Messages{
+	Templates: map[string]string{
+		ruleLen:      "длина поля %[1]s должна быть равна %[2]s",
+		ruleRegexp:   "поле %[1]s должно соответствовать регулярному выражению %[2]s",
+		ruleIn:       "поле %[1]s должно принимать одно из значений %[2]s",
+		ruleOneOf:    "поле %[1]s должно принимать одно из значений %[2]s",
+		ruleMin:      "поле %[1]s должно быть не меньше %[2]s",
+		ruleMax:      "поле %[1]s должно быть не больше %[2]s",
+		ruleRequired: "поле %[1]s обязательно для заполнения",
+		ruleNotEmpty: "поле %[1]s не должно быть пустым",
+		ruleMinLen:   "длина поля %[1]s должна быть не меньше %[2]s",
+		ruleMaxLen:   "длина поля %[1]s должна быть не больше %[2]s",
+		rulePrefix:   "поле %[1]s должно начинаться с %[2]q",
+		ruleContains: "поле %[1]s должно содержать %[2]q",
+
+		ruleGtField:        "поле %[1]s должно быть больше поля %[2]s",
+		ruleGteField:       "поле %[1]s должно быть не меньше поля %[2]s",
+		ruleLtField:        "поле %[1]s должно быть меньше поля %[2]s",
+		ruleLteField:       "поле %[1]s должно быть не больше поля %[2]s",
+		ruleEqField:        "поле %[1]s должно совпадать с полем %[2]s",
+		ruleNeField:        "поле %[1]s не должно совпадать с полем %[2]s",
+		ruleRequiredIf:     "поле %[1]s обязательно для заполнения при %[2]s",
+		ruleRequiredUnless: "поле %[1]s обязательно для заполнения, кроме случая %[2]s",
+		ruleStruct:         "%[4]v",
+	},
+	Fallback: "поле %[1]s заполнено неверно: %[4]v",
+}
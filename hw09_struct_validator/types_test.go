@@ -0,0 +1,218 @@
+package hw09_struct_validator //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+type (
+	Priority int
+	Port     uint16
+
+	Account struct {
+		Role      UserRole          `validate:"required|oneof:admin stuff"`
+		Priority  Priority          `validate:"in:1,2,3"`
+		Balance   int64             `validate:"min:-100|max:1000000"`
+		Port      Port              `validate:"min:1024"`
+		Size      uint64            `validate:"max:18446744073709551615"`
+		Ratio     float64           `validate:"min:0.5|max:1"`
+		Timeout   time.Duration     `validate:"min:1s|max:1m"`
+		CreatedAt time.Time         `validate:"required|min:2000-01-01T00:00:00Z"`
+		Nickname  *string           `validate:"minlen:3|prefix:@"`
+		Bio       string            `validate:"maxlen:10"`
+		Tags      []string          `validate:"notempty|maxlen:2|contains:-"`
+		Codes     [2]int            `validate:"oneof:1 2"`
+		Limits    map[string]int    `validate:"max:10"`
+		Owner     *User             `validate:"required"`
+		Apps      map[string]*App   `validate:"nested"`
+		Ports     *[]Port           `validate:"minlen:1|min:80"`
+		Labels    map[string]string `validate:"notempty"`
+	}
+
+	MinLenForInt struct {
+		Count int `validate:"minlen:1"`
+	}
+
+	PrefixForInt struct {
+		Count int `validate:"prefix:1"`
+	}
+
+	InvalidDuration struct {
+		Timeout time.Duration `validate:"min:10"`
+	}
+
+	NegativeUint struct {
+		Size uint `validate:"min:-1"`
+	}
+
+	InvalidTime struct {
+		CreatedAt time.Time `validate:"min:0"`
+	}
+
+	OverflowUint8 struct {
+		Level uint8 `validate:"max:300"`
+	}
+
+	OverflowInt8In struct {
+		Level int8 `validate:"in:1,200"`
+	}
+
+	NaNLimit struct {
+		Ratio float64 `validate:"min:NaN"`
+	}
+
+	Ratio struct {
+		Value float64 `validate:"min:0|max:1"`
+	}
+
+	InForTime struct {
+		CreatedAt time.Time `validate:"in:2000-01-01T00:00:00Z"`
+	}
+
+	NestedWithRequired struct {
+		App *App `validate:"required|nested"`
+	}
+)
+
+func validAccount() Account {
+	nickname := "@john"
+	ports := []Port{8080}
+
+	return Account{
+		Role:      "admin",
+		Priority:  2,
+		Balance:   -50,
+		Port:      8080,
+		Size:      1 << 63,
+		Ratio:     0.75,
+		Timeout:   30 * time.Second,
+		CreatedAt: time.Now(),
+		Nickname:  &nickname,
+		Bio:       "gopher",
+		Tags:      []string{"go-lang"},
+		Codes:     [2]int{1, 2},
+		Limits:    map[string]int{"cpu": 4},
+		Owner:     &validUser,
+		Apps:      map[string]*App{"api": {Version: "1.0.0"}, "none": nil},
+		Ports:     &ports,
+		Labels:    map[string]string{"env": "prod"},
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		if err := Validate(validAccount()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		nickname := "john"
+		ports := []Port{80, 22}
+
+		account := Account{
+			Role:      "guest",
+			Priority:  4,
+			Balance:   -101,
+			Port:      80,
+			Ratio:     1.5,
+			Timeout:   time.Hour,
+			Nickname:  &nickname,
+			Bio:       "very long biography",
+			Tags:      []string{"go", "lang", "x"},
+			Codes:     [2]int{1, 3},
+			Limits:    map[string]int{"memory": 20, "cpu": 11, "disk": 1},
+			Apps:      map[string]*App{"web": {Version: "1"}},
+			Ports:     &ports,
+			Labels:    map[string]string{},
+			CreatedAt: time.Time{},
+		}
+
+		requireValidationErrors(t, Validate(account), ValidationErrors{
+			{Field: "Role", Err: ErrOneOf},
+			{Field: "Priority", Err: ErrIn},
+			{Field: "Balance", Err: ErrMin},
+			{Field: "Port", Err: ErrMin},
+			{Field: "Ratio", Err: ErrMax},
+			{Field: "Timeout", Err: ErrMax},
+			{Field: "CreatedAt", Err: ErrRequired},
+			{Field: "CreatedAt", Err: ErrMin},
+			{Field: "Nickname", Err: ErrPrefix},
+			{Field: "Bio", Err: ErrMaxLen},
+			{Field: "Tags", Err: ErrMaxLen},
+			{Field: "Tags[0]", Err: ErrContains},
+			{Field: "Tags[1]", Err: ErrContains},
+			{Field: "Tags[2]", Err: ErrContains},
+			{Field: "Codes[1]", Err: ErrOneOf},
+			{Field: "Limits[cpu]", Err: ErrMax},
+			{Field: "Limits[memory]", Err: ErrMax},
+			{Field: "Owner", Err: ErrRequired},
+			{Field: "Apps[web].Version", Err: ErrLen},
+			{Field: "Ports[1]", Err: ErrMin},
+			{Field: "Labels", Err: ErrEmpty},
+		})
+	})
+
+	t.Run("EmptyValues", func(t *testing.T) {
+		account := validAccount()
+		account.Role = ""
+		account.Nickname = nil
+		account.Tags = []string{" "}
+		account.Ports = nil
+		account.Labels = nil
+
+		requireValidationErrors(t, Validate(account), ValidationErrors{
+			{Field: "Role", Err: ErrRequired},
+			{Field: "Role", Err: ErrOneOf},
+			{Field: "Nickname", Err: ErrMinLen},
+			{Field: "Tags[0]", Err: ErrContains},
+			{Field: "Ports", Err: ErrMinLen},
+			{Field: "Labels", Err: ErrEmpty},
+		})
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		requireValidationErrors(t, Validate(Ratio{Value: math.NaN()}), ValidationErrors{
+			{Field: "Value", Err: ErrMin},
+			{Field: "Value", Err: ErrMax},
+		})
+	})
+
+	t.Run("NestedWithRequired", func(t *testing.T) {
+		requireValidationErrors(t, Validate(NestedWithRequired{}), ValidationErrors{{Field: "App", Err: ErrRequired}})
+
+		requireValidationErrors(t, Validate(NestedWithRequired{App: &App{}}), ValidationErrors{
+			{Field: "App.Version", Err: ErrLen},
+		})
+	})
+}
+
+func TestValidateTypesProgrammerErrors(t *testing.T) {
+	tests := []struct {
+		in          interface{}
+		expectedErr error
+	}{
+		{in: MinLenForInt{}, expectedErr: ErrUnsupportedType},
+		{in: PrefixForInt{}, expectedErr: ErrUnknownRule},
+		{in: InvalidDuration{}, expectedErr: ErrInvalidRuleArg},
+		{in: NegativeUint{}, expectedErr: ErrInvalidRuleArg},
+		{in: InvalidTime{}, expectedErr: ErrInvalidRuleArg},
+		{in: OverflowUint8{}, expectedErr: ErrInvalidRuleArg},
+		{in: OverflowInt8In{}, expectedErr: ErrInvalidRuleArg},
+		{in: NaNLimit{}, expectedErr: ErrInvalidRuleArg},
+		{in: InForTime{}, expectedErr: ErrUnknownRule},
+	}
+
+	for i, tt := range tests {
+		tt := tt
+
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			if err := Validate(tt.in); !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("unexpected error: %v, expected: %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
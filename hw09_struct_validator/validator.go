@@ -1,17 +1,267 @@
 package hw09_struct_validator //nolint:golint,stylecheck
 
-type ValidationError struct {
-	Field string
-	Err   error
-}
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validateTag is a name of struct tag with validation rules.
+const validateTag = "validate"
 
-type ValidationErrors []ValidationError
+// Programmer errors, they are returned by Validate as is
+// and mean that the value or its tags are invalid.
+var (
+	ErrNotStruct       = errors.New("value is not a struct")
+	ErrInvalidTag      = errors.New("invalid validate tag")
+	ErrUnknownRule     = errors.New("unknown rule")
+	ErrInvalidRuleArg  = errors.New("invalid rule argument")
+	ErrUnsupportedType = errors.New("unsupported field type")
+)
 
-func (v ValidationErrors) Error() string {
-	panic("implement me")
+// StructValidator is implemented by structs which validate themselves.
+// Validator calls Validate after rules of fields of the struct, so it can
+// check relations between fields which can't be expressed by tags. Its
+// errors are returned in ValidationErrors. Validate must not validate the
+// struct itself by Validator, it would call Validate method again.
+type StructValidator interface {
+	Validate() error
 }
 
+// Validate validates v by the default Validator.
+// See Validator.Validate for details.
 func Validate(v interface{}) error {
-	// Place your code here
+	return defaultValidator.Validate(v)
+}
+
+// Validate validates exported fields of struct s (or pointer to struct)
+// according with rules in their validate tags. It returns ValidationErrors
+// with all errors of fields values, or a programmer error, like
+// ErrNotStruct or ErrUnknownRule, if s or tags are invalid. Structs
+// implementing StructValidator are checked by it after their fields.
+// Tags of each struct type are parsed once, Validate is safe
+// for concurrent use.
+func (v *Validator) Validate(s interface{}) error {
+	rv := reflect.ValueOf(s)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrNotStruct, s)
+	}
+
+	var errs ValidationErrors
+
+	if err := v.validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
+
+// validateStruct appends errors of fields of struct v into errs.
+// Names of fields are prefixed with prefix.
+func (v *Validator) validateStruct(s reflect.Value, prefix string, errs *ValidationErrors) error {
+	plan, err := v.planFor(s.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range plan.fields {
+		value := s.Field(field.index)
+		name := prefix + field.name
+
+		for _, rc := range field.fieldChecks {
+			if err := rc.check(value, s); err != nil {
+				*errs = append(*errs, v.newError(name, rc.rule, indirect(value), err))
+			}
+		}
+
+		if value = indirect(value); !value.IsValid() {
+			continue
+		}
+
+		if err := v.validateElems(value, name, field, errs); err != nil {
+			return err
+		}
+	}
+
+	if plan.hook != noHook {
+		v.callHook(s, plan.hook, strings.TrimSuffix(prefix, "."), errs)
+	}
+
+	return nil
+}
+
+// callHook calls Validate method of struct s at path and appends its errors
+// into errs. ValidationErrors and ValidationError returned by the method
+// are prefixed with path, other errors are wrapped into ValidationError.
+func (v *Validator) callHook(s reflect.Value, hook hookKind, path string, errs *ValidationErrors) {
+	if hook == pointerHook {
+		if !s.CanAddr() {
+			// A copy of the struct is validated, if it is passed by value.
+			addressable := reflect.New(s.Type()).Elem()
+			addressable.Set(s)
+			s = addressable
+		}
+
+		s = s.Addr()
+	}
+
+	err := s.Interface().(StructValidator).Validate()
+	if err == nil {
+		return
+	}
+
+	var (
+		hookErrs ValidationErrors
+		hookErr  ValidationError
+	)
+
+	switch {
+	case errors.As(err, &hookErrs):
+	case errors.As(err, &hookErr):
+		hookErrs = ValidationErrors{hookErr}
+	default:
+		*errs = append(*errs, ValidationError{Field: path, Rule: ruleStruct, Err: err, translate: v.translator.Translate})
+		return
+	}
+
+	for _, e := range hookErrs {
+		e.Field = joinPath(path, e.Field)
+		if e.translate == nil {
+			e.translate = v.translator.Translate
+		}
+
+		*errs = append(*errs, e)
+	}
+}
+
+// joinPath returns path of field name of struct at path.
+func joinPath(path, name string) string {
+	switch {
+	case path == "":
+		return name
+	case name == "":
+		return path
+	default:
+		return path + "." + name
+	}
+}
+
+// validateElems appends errors of value of field name or its elements,
+// if it is a collection, into errs. Errors of maps elements are sorted by keys.
+func (v *Validator) validateElems(value reflect.Value, name string, field fieldPlan, errs *ValidationErrors) error {
+	if !field.collection {
+		return v.validateValue(value, name, -1, field, errs)
+	}
+
+	if value.Kind() != reflect.Map {
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateValue(value.Index(i), name, i, field, errs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	keys := value.MapKeys()
+	names := make([]string, len(keys))
+
+	for i, key := range keys {
+		names[i] = fmt.Sprintf("%s[%v]", name, key)
+	}
+
+	sort.Sort(byName{keys: keys, names: names})
+
+	for i, key := range keys {
+		if err := v.validateValue(value.MapIndex(key), names[i], -1, field, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateValue appends errors of value of field name into errs.
+// If index is not negative, value is an element of the field with the index.
+// Nil pointers are skipped.
+func (v *Validator) validateValue(value reflect.Value, name string, index int, field fieldPlan, errs *ValidationErrors) error {
+	if value = indirect(value); !value.IsValid() {
+		return nil
+	}
+
+	if field.nested {
+		return v.validateStruct(value, elemName(name, index)+".", errs)
+	}
+
+	for _, rc := range field.checks {
+		err := rc.check(value)
+
+		switch {
+		case err == nil:
+		case isProgrammerError(err):
+			return fmt.Errorf("field %s: %w", elemName(name, index), err)
+		default:
+			*errs = append(*errs, v.newError(elemName(name, index), rc.rule, value, err))
+		}
+	}
+
+	return nil
+}
+
+// byName sorts map keys by names of their elements.
+type byName struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (b byName) Len() int           { return len(b.keys) }
+func (b byName) Less(i, j int) bool { return b.names[i] < b.names[j] }
+func (b byName) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.names[i], b.names[j] = b.names[j], b.names[i]
+}
+
+// newError returns ValidationError of value of field name by rule r.
+func (v *Validator) newError(name string, r rule, value reflect.Value, err error) ValidationError {
+	var actual interface{}
+	if value.IsValid() && value.CanInterface() {
+		actual = value.Interface()
+	}
+
+	return ValidationError{
+		Field:     name,
+		Rule:      r.name,
+		Arg:       r.arg,
+		Value:     actual,
+		Err:       err,
+		translate: v.translator.Translate,
+	}
+}
+
+// isProgrammerError reports whether err returned by a rule
+// means that the rule is used incorrectly.
+func isProgrammerError(err error) bool {
+	return errors.Is(err, ErrInvalidRuleArg) || errors.Is(err, ErrUnsupportedType)
+}
+
+// elemName returns name of element of field name with index,
+// or name itself if index is negative. Names are built only
+// when they are needed, because most values are valid.
+func elemName(name string, index int) string {
+	if index < 0 {
+		return name
+	}
+
+	return name + "[" + strconv.Itoa(index) + "]"
+}
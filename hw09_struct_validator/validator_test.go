@@ -2,6 +2,7 @@ package hw09_struct_validator //nolint:golint,stylecheck
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -34,23 +35,192 @@ type (
 		Code int    `validate:"in:200,404,500"`
 		Body string `json:"omitempty"`
 	}
+
+	Release struct {
+		App     App   `validate:"nested"`
+		Base    *App  `validate:"nested"`
+		Plugins []App `validate:"nested"`
+		Ports   []int `validate:"min:1024|max:65535"`
+		Tags    []string
+		Owner   User
+		secret  string `validate:"len:10"`
+		draft   App    `validate:"nested"`
+	}
+
+	UnknownRule struct {
+		Name string `validate:"size:10"`
+	}
+
+	RuleForOtherType struct {
+		Age int `validate:"len:2"`
+	}
+
+	InvalidLen struct {
+		Name string `validate:"len:ten"`
+	}
+
+	InvalidRegexp struct {
+		Name string `validate:"regexp:[a-"`
+	}
+
+	InvalidIn struct {
+		Code int `validate:"in:200,ok"`
+	}
+
+	EmptyRule struct {
+		Code int `validate:"min:1|"`
+	}
+
+	UnsupportedType struct {
+		Enabled bool `validate:"min:1"`
+	}
+
+	NestedNotStruct struct {
+		Name string `validate:"nested"`
+	}
+
+	NestedCombined struct {
+		App App `validate:"nested|len:5"`
+	}
 )
 
+var validUser = User{
+	ID:     "5f1b7c1e-1a2b-4c3d-8e9f-0a1b2c3d4e5f",
+	Name:   "John",
+	Age:    30,
+	Email:  "john@example.com",
+	Role:   "admin",
+	Phones: []string{"79001234567", "79007654321"},
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		in          interface{}
 		expectedErr error
 	}{
+		{in: validUser},
+		{in: &validUser},
 		{
-			// Place your code here
+			in: User{
+				ID:     "short",
+				Age:    17,
+				Email:  "john.example.com",
+				Role:   "guest",
+				Phones: []string{"79001234567", "123"},
+			},
+			expectedErr: ValidationErrors{
+				{Field: "ID", Err: ErrLen},
+				{Field: "Age", Err: ErrMin},
+				{Field: "Email", Err: ErrRegexp},
+				{Field: "Role", Err: ErrIn},
+				{Field: "Phones[1]", Err: ErrLen},
+			},
 		},
-		// ...
-		// Place your code here
+		{
+			in:          User{ID: validUser.ID, Age: 51, Email: validUser.Email, Role: "stuff"},
+			expectedErr: ValidationErrors{{Field: "Age", Err: ErrMax}},
+		},
+		{in: App{Version: "1.0.0"}},
+		{in: App{Version: "ёжик!"}},
+		{
+			in:          App{Version: "1.0"},
+			expectedErr: ValidationErrors{{Field: "Version", Err: ErrLen}},
+		},
+		{in: Token{Header: []byte("header")}},
+		{in: Response{Code: 404}},
+		{
+			in:          Response{Code: 302},
+			expectedErr: ValidationErrors{{Field: "Code", Err: ErrIn}},
+		},
+		// Unexported fields aren't validated, even nested ones.
+		{in: Release{App: App{Version: "1.0.0"}, Ports: []int{8080}, secret: "short", draft: App{Version: "1"}}},
+		{
+			in: Release{
+				App:     App{Version: "1.0"},
+				Base:    &App{Version: "0.1"},
+				Plugins: []App{{Version: "2.0.0"}, {Version: "2"}},
+				Ports:   []int{80, 8080, 70000},
+				Owner:   User{Age: 1},
+			},
+			expectedErr: ValidationErrors{
+				{Field: "App.Version", Err: ErrLen},
+				{Field: "Base.Version", Err: ErrLen},
+				{Field: "Plugins[1].Version", Err: ErrLen},
+				{Field: "Ports[0]", Err: ErrMin},
+				{Field: "Ports[2]", Err: ErrMax},
+			},
+		},
+		{in: "string", expectedErr: ErrNotStruct},
+		{in: (*User)(nil), expectedErr: ErrNotStruct},
+		{in: nil, expectedErr: ErrNotStruct},
+		{in: UnknownRule{}, expectedErr: ErrUnknownRule},
+		{in: RuleForOtherType{}, expectedErr: ErrUnknownRule},
+		{in: InvalidLen{}, expectedErr: ErrInvalidRuleArg},
+		{in: InvalidRegexp{}, expectedErr: ErrInvalidRuleArg},
+		{in: InvalidIn{}, expectedErr: ErrInvalidRuleArg},
+		{in: EmptyRule{}, expectedErr: ErrInvalidTag},
+		{in: UnsupportedType{}, expectedErr: ErrUnsupportedType},
+		{in: NestedNotStruct{}, expectedErr: ErrUnsupportedType},
+		{in: NestedCombined{}, expectedErr: ErrInvalidTag},
 	}
 
 	for i, tt := range tests {
+		tt := tt
+
 		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
-			// Place your code here
+			err := Validate(tt.in)
+
+			var expected ValidationErrors
+			if errors.As(tt.expectedErr, &expected) {
+				requireValidationErrors(t, err, expected)
+				return
+			}
+
+			if !errors.Is(err, tt.expectedErr) || (err == nil) != (tt.expectedErr == nil) {
+				t.Fatalf("unexpected error: %v, expected: %v", err, tt.expectedErr)
+			}
+
+			var validationErrs ValidationErrors
+			if errors.As(err, &validationErrs) {
+				t.Fatalf("programmer error is returned as validation errors: %v", err)
+			}
 		})
 	}
 }
+
+func TestValidationErrorsError(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "Age", Err: fmt.Errorf("%w: 17, expected at least 18", ErrMin)},
+		{Field: "Role", Err: ErrIn},
+	}
+
+	expected := "Age: less than minimum: 17, expected at least 18; Role: isn't in allowed set"
+	if errs.Error() != expected {
+		t.Fatalf("unexpected message: %q, expected: %q", errs.Error(), expected)
+	}
+
+	if !errors.Is(errs[0], ErrMin) {
+		t.Fatalf("validation error doesn't wrap %v", ErrMin)
+	}
+}
+
+// requireValidationErrors checks that err is ValidationErrors with fields
+// and errors of expected, which are compared by errors.Is.
+func requireValidationErrors(t *testing.T, err error, expected ValidationErrors) {
+	t.Helper()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("unexpected error: %v, expected validation errors: %v", err, expected)
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors: %v, expected: %v", errs, expected)
+	}
+
+	for i := range expected {
+		if errs[i].Field != expected[i].Field || !errors.Is(errs[i].Err, expected[i].Err) {
+			t.Fatalf("unexpected error %d: %v, expected: %v", i, errs[i], expected[i])
+		}
+	}
+}